@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/dbaas-opensearch-adapter/metrics"
 	"github.com/gorilla/mux"
 	"github.com/opensearch-project/opensearch-go/opensearchapi"
 )
@@ -111,8 +112,9 @@ type Curator struct {
 	client   *http.Client
 }
 
-var ErrBackupNotFound = errors.New("backup not found")
-var ErrCuratorUnavailable = errors.New("curator return internal server error")
+var ErrBackupNotFound = common.NewNotFoundError(common.BackupNotFoundCode, errors.New("backup not found"))
+var ErrCuratorUnavailable = common.NewError(http.StatusInternalServerError, common.CuratorErrorCode,
+	errors.New("curator return internal server error"))
 
 type BackupProvider struct {
 	client     common.Client
@@ -121,17 +123,21 @@ type BackupProvider struct {
 	Curator    *Curator
 }
 
-func NewBackupProvider(opensearchClient common.Client, curatorClient *http.Client, repoRoot string) *BackupProvider {
+// NewCurator creates client of curator which performs backups of the cluster with the given identifier
+func NewCurator(cluster string, url string, username string, password string, client *http.Client) *Curator {
+	return &Curator{
+		url:      url,
+		username: username,
+		password: password,
+		client:   metrics.InstrumentCuratorClient(cluster, client),
+	}
+}
+
+func NewBackupProvider(opensearchClient common.Client, curator *Curator, repoRoot string) *BackupProvider {
 	logger.Info(fmt.Sprintf("Creating new backup provider, repository root is '%s'", repoRoot))
 	if !strings.HasSuffix(repoRoot, "/") {
 		repoRoot = repoRoot + "/"
 	}
-	curator := &Curator{
-		url:      common.GetEnv("CURATOR_ADDRESS", ""),
-		username: common.GetEnv("CURATOR_USERNAME", ""),
-		password: common.GetEnv("CURATOR_PASSWORD", ""),
-		client:   curatorClient,
-	}
 	backupService := &BackupProvider{
 		client:     opensearchClient,
 		indexNames: common.NewIndexAdapter(),
@@ -155,8 +161,7 @@ func (bp BackupProvider) CollectBackupHandler() func(w http.ResponseWriter, r *h
 		err := decoder.Decode(&databases)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to decode request from JSON", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
 		defer func(Body io.ReadCloser) {
@@ -169,24 +174,21 @@ func (bp BackupProvider) CollectBackupHandler() func(w http.ResponseWriter, r *h
 		backupID, err := bp.CollectBackup(databases, ctx)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to create snapshot", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 
 		response, err := bp.TrackBackup(backupID, ctx)
 		if err != nil {
 			logger.ErrorContext(ctx, "failed to fetch job status from curator", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 
 		responseBody, err := json.Marshal(response)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		w.WriteHeader(http.StatusAccepted)
@@ -204,21 +206,25 @@ func (bp BackupProvider) DeleteBackupHandler() func(w http.ResponseWriter, r *ht
 		response, err := bp.DeleteBackup(backupID, ctx)
 		if err != nil {
 			logger.ErrorContext(ctx, "failed to delete backup", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 
+		defer response.Body.Close()
 		body, err := io.ReadAll(response.Body)
 		if err != nil {
 			logger.ErrorContext(ctx, "failed to read from http response", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
+			return
 		}
 
+		if response.StatusCode == http.StatusNotFound {
+			common.WriteError(w, ErrBackupNotFound, ctx)
+			return
+		}
 		if response.StatusCode > 200 {
-			w.WriteHeader(response.StatusCode)
-			_, _ = w.Write(body)
+			common.WriteError(w, common.NewError(response.StatusCode, common.CuratorErrorCode,
+				fmt.Errorf("curator failed to delete '%s' backup: %s", backupID, body)), ctx)
 			return
 		}
 
@@ -235,21 +241,15 @@ func (bp BackupProvider) TrackBackupHandler() func(w http.ResponseWriter, r *htt
 		trackID := vars["backupID"]
 		response, err := bp.TrackBackup(trackID, ctx)
 		if err != nil {
-			if errors.Is(err, ErrBackupNotFound) {
-				w.WriteHeader(http.StatusNotFound)
-			} else {
-				logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
-				w.WriteHeader(http.StatusInternalServerError)
-				_, _ = w.Write([]byte(err.Error()))
-				return
-			}
+			logger.ErrorContext(ctx, "Failed to track backup", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
 		}
 
 		responseBody, err := json.Marshal(response)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		_, _ = w.Write(responseBody)
@@ -267,8 +267,7 @@ func (bp BackupProvider) RestoreBackupHandler(repo string, basePath string) func
 		err := decoder.Decode(&databases)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to decode request from JSON", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
 		defer r.Body.Close()
@@ -277,33 +276,26 @@ func (bp BackupProvider) RestoreBackupHandler(repo string, basePath string) func
 		changedNameDb, err := bp.RestoreBackup(backupID, databases, repo, regenerateNames, ctx)
 		if err != nil {
 			logMsg := "failed to restore backup, internal server error occur"
-			statusCode := http.StatusInternalServerError
 			if errors.Is(err, ErrBackupNotFound) {
 				logMsg = "failed to restore backup, the backup is not found"
-				statusCode = http.StatusNotFound
 			}
 			logger.ErrorContext(ctx, logMsg, slog.String("error", err.Error()))
-			w.WriteHeader(statusCode)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 
 		response, err := bp.TrackRestore(backupID, ctx, changedNameDb)
 		if err != nil {
 			logger.ErrorContext(ctx, "restore backup is failed", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, err = w.Write([]byte(err.Error()))
-			if err != nil {
-				logger.ErrorContext(ctx, "failed to write bytes to the http body response")
-			}
+			common.WriteError(w, err, ctx)
+			return
 		}
 
 		if regenerateNames {
 			indices, err := bp.getActualIndices(backupID, repo, changedNameDb, ctx)
 			if err != nil {
 				logger.ErrorContext(ctx, "Failed to receive indices from snapshot", slog.Any("error", err))
-				w.WriteHeader(http.StatusInternalServerError)
-				_, _ = w.Write([]byte(err.Error()))
+				common.WriteError(w, err, ctx)
 				return
 			}
 			trackPath := fmt.Sprintf("%s/backups/track/restoring/backups/%s/indices/%s",
@@ -317,8 +309,7 @@ func (bp BackupProvider) RestoreBackupHandler(repo string, basePath string) func
 		responseBody, err := json.Marshal(response)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		_, _ = w.Write(responseBody)
@@ -334,8 +325,7 @@ func (bp BackupProvider) RestorationBackupHandler(repo string, basePath string)
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to decode request body", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
 		defer r.Body.Close()
@@ -343,43 +333,31 @@ func (bp BackupProvider) RestorationBackupHandler(repo string, basePath string)
 		err = json.Unmarshal(body, &req)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to unmarshal request from JSON", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
 
 		changedNameDb, err, trackId := bp.ProcessRestorationRequest(backupID, req, ctx)
 		if err != nil {
 			logger.ErrorContext(ctx, "failed to process restoration", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, err = w.Write([]byte(err.Error()))
-			if err != nil {
-				logger.ErrorContext(ctx, "failed to write bytes into http response body", slog.String("error", err.Error()))
-			}
+			common.WriteError(w, err, ctx)
 			return
 		}
 
 		response, err := bp.TrackRestore(trackId, ctx, changedNameDb)
 		if err != nil {
-			errStatusCode := http.StatusInternalServerError
 			logMsg := "an internal server error occurred while attempting to retrieve the recovery"
 			if errors.Is(err, ErrBackupNotFound) {
 				logMsg = "track restore not found"
-				errStatusCode = http.StatusNotFound
 			}
 			logger.ErrorContext(ctx, logMsg, slog.String("error", err.Error()))
-			w.WriteHeader(errStatusCode)
-			_, err = w.Write([]byte(err.Error()))
-			if err != nil {
-				logger.ErrorContext(ctx, "failed to write bytes into the http response", slog.String("error", err.Error()))
-			}
+			common.WriteError(w, err, ctx)
 			return
 		}
 		responseBody, err := json.Marshal(response)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		_, _ = w.Write(responseBody)
@@ -394,21 +372,19 @@ func (bp BackupProvider) TrackRestoreFromTrackIdHandler(fromRepo string) func(w
 		backupID := vars["backupID"]
 		response, err := bp.TrackRestore(backupID, ctx, nil)
 		if err != nil {
-			errStatusCode := http.StatusInternalServerError
 			logMsg := "an internal server error occurred while attempting to retrieve the recovery"
 			if errors.Is(err, ErrBackupNotFound) {
 				logMsg = "track restore not found"
-				errStatusCode = http.StatusNotFound
 			}
 			logger.ErrorContext(ctx, logMsg, slog.String("error", err.Error()))
-			w.WriteHeader(errStatusCode)
+			common.WriteError(w, err, ctx)
+			return
 		}
 		var responseBody []byte
 		responseBody, err = json.Marshal(response)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.String("error", err.Error()))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		_, _ = w.Write(responseBody)
@@ -427,8 +403,7 @@ func (bp BackupProvider) TrackRestoreFromIndicesHandler(fromRepo string) func(w
 		responseBody, err := json.Marshal(response)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		_, _ = w.Write(responseBody)
@@ -504,7 +479,7 @@ func (bp BackupProvider) DeleteBackup(backupID string, ctx context.Context) (*ht
 func (bp BackupProvider) RestoreBackup(backupId string, dbs []string, fromRepo string, regenerateNames bool, ctx context.Context) (map[string]string, error) {
 	if len(dbs) == 0 {
 		logger.ErrorContext(ctx, "Database prefixes to restore are not specified")
-		return nil, errors.New("database prefixes to restore are not specified")
+		return nil, common.NewBadRequestError(common.InvalidRequestCode, errors.New("database prefixes to restore are not specified"))
 	}
 	var indices []string
 	var err error
@@ -593,7 +568,7 @@ func (bp BackupProvider) RestoreBackup(backupId string, dbs []string, fromRepo s
 func (bp BackupProvider) ProcessRestorationRequest(backupId string, restorationRequest RestorationRequest, ctx context.Context) (map[string]string, error, string) {
 	if len(restorationRequest.Databases) == 0 {
 		logger.ErrorContext(ctx, "Databases to restore are not specified")
-		return nil, errors.New("database to restore are not specified"), ""
+		return nil, common.NewBadRequestError(common.InvalidRequestCode, errors.New("database to restore are not specified")), ""
 	}
 	var renames, dbs []string
 	var changedDbNames map[string]string
@@ -666,10 +641,12 @@ func (bp BackupProvider) checkPrefixUniqueness(prefix string, ctx context.Contex
 		}
 		for element, user := range users {
 			if strings.HasPrefix(element, prefix) {
-				return false, fmt.Errorf("provided prefix already exists or a part of another prefix: %+v", prefix)
+				return false, common.NewConflictError(common.PrefixConflictCode,
+					fmt.Errorf("provided prefix already exists or a part of another prefix: %+v", prefix))
 			}
 			if user.Attributes[resourcePrefixAttributeName] != "" && strings.HasPrefix(user.Attributes[resourcePrefixAttributeName], prefix) {
-				return false, fmt.Errorf("provided prefix already exists or a part of another prefix: %+v", prefix)
+				return false, common.NewConflictError(common.PrefixConflictCode,
+					fmt.Errorf("provided prefix already exists or a part of another prefix: %+v", prefix))
 			}
 		}
 	} else if response.StatusCode == http.StatusNotFound {
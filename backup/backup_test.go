@@ -40,7 +40,7 @@ func setup() {
 	curatorClient := &http.Client{
 		Transport: &common.TransportStub{},
 	}
-	backupProvider = *NewBackupProvider(opensearchClient, curatorClient, "snapshots")
+	backupProvider = *NewBackupProvider(opensearchClient, NewCurator("", "", "", "", curatorClient), "snapshots")
 	ctx = context.WithValue(context.Background(), common.RequestIdKey, common.GenerateUUID())
 }
 
@@ -22,8 +22,11 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Netcracker/dbaas-opensearch-adapter/cluster"
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
@@ -37,6 +40,11 @@ const (
 	DbaasMetadata        = "dbaas_opensearch_metadata"
 	DeletedStatus        = "DELETED"
 	DeletionFailedStatus = "DELETE_FAILED"
+	// resourcePrefixDelimiter separates resource prefix from the rest of names of resources created for the database
+	resourcePrefixDelimiter = "_"
+	// metadataSearchSize is the maximum number of metadata documents received with one request,
+	// it is equal to default `index.max_result_window` of OpenSearch
+	metadataSearchSize = 10000
 )
 
 var logger = common.GetLogger()
@@ -46,7 +54,19 @@ type BaseProvider struct {
 	mutex             *sync.Mutex
 	passwordGenerator PasswordGenerator
 	ApiVersion        string
-	recoveryState     string
+	// recoveryJob is the latest users recovery job, it is guarded by recoveryMutex
+	recoveryJob   *RecoveryJob
+	recoveryMutex *sync.Mutex
+	// recoveryGroup tracks running users recovery and periodic background processes, recoveryStop is closed to
+	// interrupt them on shutdown
+	recoveryGroup *sync.WaitGroup
+	recoveryStop  chan struct{}
+	// softDeleteRetention keeps resources dropped by bulk drop until it is over, soft delete is disabled if it is zero
+	softDeleteRetention time.Duration
+	// roleTypes are role types of users created for databases, built-in role types are used if it is empty
+	roleTypes []RoleTypeDefinition
+	// classifierLocks serialise creation of databases with the same classifier
+	classifierLocks *keyedMutex
 }
 
 type DbCreateRequest struct {
@@ -59,9 +79,12 @@ type DbCreateRequest struct {
 }
 
 type Settings struct {
-	ResourcePrefix bool        `json:"resourcePrefix,omitempty"`
-	CreateOnly     []string    `json:"createOnly,omitempty"`
-	IndexSettings  interface{} `json:"indexSettings,omitempty"`
+	ResourcePrefix  bool        `json:"resourcePrefix,omitempty"`
+	RotatePasswords bool        `json:"rotatePasswords,omitempty"`
+	CreateOnly      []string    `json:"createOnly,omitempty"`
+	IndexSettings   interface{} `json:"indexSettings,omitempty"`
+	// AdditionalPermissions are granted to users of the database by role types in addition to permissions of role types
+	AdditionalPermissions map[string]AdditionalPermissions `json:"additionalPermissions,omitempty"`
 }
 
 type DbCreateResponse struct {
@@ -76,51 +99,98 @@ type DbCreateResponseMultiUser struct {
 	Resources            []dao.DbResource              `json:"resources"`
 }
 
+// RollbackError is returned when database creation fails after some resources are already created,
+// it contains the initial error and the statuses of created resources removal.
+type RollbackError struct {
+	Cause     error
+	Resources []dao.DbResource
+}
+
+type RollbackResponse struct {
+	RollbackSucceeded   bool             `json:"rollbackSucceeded"`
+	RolledBackResources []dao.DbResource `json:"rolledBackResources"`
+}
+
 type Metadata struct {
 	Found  bool                   `json:"found"`
 	Source map[string]interface{} `json:"_source"`
 }
 
+type LogicalDatabase struct {
+	Name    string   `json:"name"`
+	Indices []string `json:"indices"`
+}
+
+type metadataSearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			ID     string                 `json:"_id"`
+			Source map[string]interface{} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
 type IndexTemplate struct {
 	Name          string      `json:"name"`
 	IndexTemplate interface{} `json:"index_template"`
 }
 
 func NewBaseProvider(opensearch *cluster.Opensearch) *BaseProvider {
-	return &BaseProvider{
+	baseProvider := &BaseProvider{
 		opensearch:        opensearch,
 		mutex:             &sync.Mutex{},
 		passwordGenerator: NewPasswordGenerator(),
-		recoveryState:     RecoveryIdleState,
+		recoveryMutex:     &sync.Mutex{},
+		recoveryGroup:     &sync.WaitGroup{},
+		recoveryStop:      make(chan struct{}),
+		classifierLocks:   newKeyedMutex(),
+	}
+	baseProvider.setRecoveryState(RecoveryIdleState)
+	return baseProvider
+}
+
+// clusterId returns identifier of OpenSearch cluster which is used in metrics
+func (bp BaseProvider) clusterId() string {
+	if bp.opensearch == nil {
+		return ""
 	}
+	return bp.opensearch.Id
 }
 
 func (bp BaseProvider) CreateDatabaseHandler() func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := common.PrepareContext(r)
 		logger.InfoContext(ctx, "Request to create new database is received")
-		decoder := json.NewDecoder(r.Body)
 		var dbCreateRequest DbCreateRequest
-		err := decoder.Decode(&dbCreateRequest)
+		violations, err := decodeRequest(r.Body, &dbCreateRequest)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to decode request in create database handler", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
 		defer r.Body.Close()
+		violations = append(violations, bp.validateDbCreateRequest(dbCreateRequest)...)
+		if len(violations) > 0 {
+			err = newValidationError(violations)
+			logger.ErrorContext(ctx, "Create database request is not valid", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
 		response, err := bp.createDatabase(dbCreateRequest, ctx)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to create database", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			var rollbackErr *RollbackError
+			if errors.As(err, &rollbackErr) {
+				err = common.NewError(http.StatusInternalServerError, common.InternalErrorCode, err).
+					WithDetails(rollbackErr.Response())
+			}
+			common.WriteError(w, err, ctx)
 			return
 		}
 		responseBody, err := json.Marshal(response)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed during response serialization in create database handler", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		w.WriteHeader(http.StatusCreated)
@@ -131,19 +201,24 @@ func (bp BaseProvider) CreateDatabaseHandler() func(w http.ResponseWriter, r *ht
 func (bp BaseProvider) ListDatabasesHandler() func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := common.PrepareContext(r)
-		logger.InfoContext(ctx, "Request to get indices list is received")
-		databases, err := bp.listDatabases()
+		var databases interface{}
+		var err error
+		if r.URL.Query().Get("flat") == "true" {
+			logger.InfoContext(ctx, "Request to get indices list is received")
+			databases, err = bp.listDatabases()
+		} else {
+			logger.InfoContext(ctx, "Request to get logical databases list is received")
+			databases, err = bp.listLogicalDatabases(ctx)
+		}
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to get indices list", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		listIndicesBytes, err := json.Marshal(databases)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to serialize indices list", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
@@ -160,13 +235,35 @@ func (bp BaseProvider) BulkDropResourceHandler() func(w http.ResponseWriter, r *
 		err := decoder.Decode(&resources)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to decode request in delete resources method", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
 		defer r.Body.Close()
 
-		deletedResources := bp.deleteResources(resources, ctx)
+		if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun")); dryRun {
+			resolvedResources, err := bp.resolveResources(resources, ctx)
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to resolve resources to delete", slog.Any("error", err))
+				common.WriteError(w, err, ctx)
+				return
+			}
+			bytesResult, err := json.Marshal(resolvedResources)
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to serialize resources list", slog.Any("error", err))
+				common.WriteError(w, err, ctx)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(bytesResult)
+			return
+		}
+
+		var deletedResources []dao.DbResource
+		if bp.softDeleteRetention > 0 {
+			deletedResources = bp.softDeleteResources(resources, ctx)
+		} else {
+			deletedResources = bp.deleteResources(resources, ctx)
+		}
 		failedResources := getResourcesWithFailedStatus(deletedResources)
 		var resourcesToReturn []dao.DbResource
 		if len(failedResources) > 0 {
@@ -179,8 +276,7 @@ func (bp BaseProvider) BulkDropResourceHandler() func(w http.ResponseWriter, r *
 		bytesResult, err := json.Marshal(resourcesToReturn)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to serialize resources list", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		_, _ = w.Write(bytesResult)
@@ -197,15 +293,13 @@ func (bp BaseProvider) UpdateMetadataHandler() func(w http.ResponseWriter, r *ht
 		err := decoder.Decode(&metadata)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to decode request in update metadata method", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
 		_, err = bp.updateMetadata(indexName, metadata, ctx)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to update metadata for index", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
@@ -219,13 +313,12 @@ func (bp BaseProvider) SupportsHandler() func(w http.ResponseWriter, r *http.Req
 		supports := common.Supports{
 			Settings:          true,
 			Users:             true,
-			DescribeDatabases: false,
+			DescribeDatabases: true,
 		}
 		responseBody, err := json.Marshal(supports)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to serialize information about supported features", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		_, _ = w.Write(responseBody)
@@ -233,47 +326,56 @@ func (bp BaseProvider) SupportsHandler() func(w http.ResponseWriter, r *http.Req
 }
 
 func (bp BaseProvider) EnsureAggregationIndex() {
+	bp.ensureIndex(DbaasMetadata)
+}
+
+// EnsureRecoveryJobsIndex creates the index which keeps users recovery jobs, so their state survives restarts.
+func (bp BaseProvider) EnsureRecoveryJobsIndex() {
+	bp.ensureIndex(RecoveryJobsIndex)
+}
+
+func (bp BaseProvider) ensureIndex(name string) {
 	bp.mutex.Lock()
 	defer bp.mutex.Unlock()
 	existsRequest := opensearchapi.IndicesExistsRequest{
-		Index: []string{DbaasMetadata},
+		Index: []string{name},
 	}
 	ctx := context.WithValue(context.Background(), common.RequestIdKey, common.GenerateUUID())
 	exist, err := existsRequest.Do(ctx, bp.opensearch.Client)
 	if err != nil {
-		logger.ErrorContext(ctx, fmt.Sprintf("Failed to check if '%s' index exists", DbaasMetadata), slog.Any("error", err))
+		logger.ErrorContext(ctx, fmt.Sprintf("Failed to check if '%s' index exists", name), slog.Any("error", err))
 		panic(err)
 	}
 	logger.DebugContext(ctx, fmt.Sprintf("Check if index exists: %v", exist))
 	if exist.StatusCode == 200 {
-		logger.DebugContext(ctx, fmt.Sprintf("'%s' index already exists", DbaasMetadata))
+		logger.DebugContext(ctx, fmt.Sprintf("'%s' index already exists", name))
 		return
 	}
 	createRequest := opensearchapi.IndicesCreateRequest{
-		Index: DbaasMetadata,
+		Index: name,
 	}
 	createResponse, err := createRequest.Do(context.Background(), bp.opensearch.Client)
 	if err != nil {
 		exist, err = existsRequest.Do(context.Background(), bp.opensearch.Client)
 		if err != nil {
-			logger.ErrorContext(ctx, fmt.Sprintf("Failed to check if '%s' index exists", DbaasMetadata), slog.Any("error", err))
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to check if '%s' index exists", name), slog.Any("error", err))
 			panic(err)
 		}
 		logger.DebugContext(ctx, fmt.Sprintf("Check if index exists: %v", exist))
 		if exist.StatusCode == 200 {
-			logger.DebugContext(ctx, fmt.Sprintf("'%s' index already exists", DbaasMetadata))
+			logger.DebugContext(ctx, fmt.Sprintf("'%s' index already exists", name))
 			return
 		}
-		logger.ErrorContext(ctx, fmt.Sprintf("Failed to create '%s' index", DbaasMetadata), slog.Any("error", err))
+		logger.ErrorContext(ctx, fmt.Sprintf("Failed to create '%s' index", name), slog.Any("error", err))
 		panic(slog.Any("error", err))
 	}
 	defer createResponse.Body.Close()
 	if createResponse.StatusCode != http.StatusCreated && createResponse.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(createResponse.Body)
-		panic(fmt.Sprintf("%s index cannot be created because of error: [%d] %s", DbaasMetadata,
+		panic(fmt.Sprintf("%s index cannot be created because of error: [%d] %s", name,
 			createResponse.StatusCode, string(body)))
 	}
-	logger.DebugContext(ctx, fmt.Sprintf("'%s' index is created", DbaasMetadata))
+	logger.DebugContext(ctx, fmt.Sprintf("'%s' index is created", name))
 }
 
 func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx context.Context) (interface{}, error) {
@@ -284,13 +386,13 @@ func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx con
 	var microserviceName string
 	logger.InfoContext(ctx, fmt.Sprintf("Creating new database for requests, dbName: '%s', username: '%s', metadata: '%+v', settings: '%+v'",
 		requestOnCreateDb.DbName, requestOnCreateDb.Username, requestOnCreateDb.Metadata, requestOnCreateDb.Settings))
-	if classifier, ok := requestOnCreateDb.Metadata["classifier"]; ok {
-		if requestNamespace, ok := classifier.(map[string]interface{})["namespace"]; ok {
-			namespace = requestNamespace.(string)
+	if classifier, ok := requestOnCreateDb.Metadata["classifier"].(map[string]interface{}); ok {
+		if requestNamespace, ok := classifier["namespace"].(string); ok {
+			namespace = requestNamespace
 		}
 	}
-	if requestMicroserviceName, ok := requestOnCreateDb.Metadata["microserviceName"]; ok {
-		microserviceName = requestMicroserviceName.(string)
+	if requestMicroserviceName, ok := requestOnCreateDb.Metadata["microserviceName"].(string); ok {
+		microserviceName = requestMicroserviceName
 	}
 
 	if requestOnCreateDb.Settings.ResourcePrefix {
@@ -318,7 +420,8 @@ func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx con
 		resources = append(resources, dao.DbResource{Kind: common.ResourcePrefixKind, Name: prefix})
 	} else {
 		if bp.ApiVersion == common.ApiV2 {
-			return nil, fmt.Errorf("'resourcePrefix' must be set to 'true' for v2 version of OpenSearch DBaaS adapter")
+			return nil, common.NewBadRequestError(common.InvalidRequestCode,
+				errors.New("'resourcePrefix' must be set to 'true' for v2 version of OpenSearch DBaaS adapter"))
 		}
 		prefix = requestOnCreateDb.NamePrefix
 		if prefix == "" {
@@ -326,6 +429,20 @@ func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx con
 		}
 	}
 
+	unlock, err := bp.lockClassifier(requestOnCreateDb)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	existing, err := bp.getExistingDatabase(requestOnCreateDb, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		logger.InfoContext(ctx, "Database with the same classifier already exists, its connection properties are returned")
+		return existing, nil
+	}
+
 	if ok, err := common.CheckPrefixUniqueness(prefix, ctx, bp.opensearch.Client); !ok {
 		if err != nil {
 			return nil, err
@@ -352,14 +469,17 @@ func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx con
 	var indexName string
 	var username string
 	var password string
-	var err error
+	// created contains only resources produced by this request, they are removed if creation fails
+	var created []dao.DbResource
+	usersByRoleType := make(map[string][]string)
 	for _, resource := range resourcesToCreate {
 		if resource == common.IndexKind {
 			indexName, err = bp.createIndex(requestOnCreateDb, prefix, ctx)
 			if err != nil {
-				return nil, err
+				return nil, bp.rollback(created, err, ctx)
 			}
 			resources = append(resources, dao.DbResource{Kind: common.IndexKind, Name: indexName})
+			created = append(created, dao.DbResource{Kind: common.IndexKind, Name: indexName})
 		}
 		if resource == common.UserKind {
 			var dbName string
@@ -372,11 +492,24 @@ func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx con
 			}
 			var securityResources []dao.DbResource
 			if bp.ApiVersion == common.ApiV1 {
+				existingUser := false
+				if username != "" {
+					var user *User
+					user, err = bp.GetUser(username)
+					if err != nil {
+						return nil, bp.rollback(created, err, ctx)
+					}
+					existingUser = user != nil
+				}
 				username, password, securityResources, err =
 					bp.createOrUpdateUser(username, requestOnCreateDb.Password, dbName, AdminRoleType, ctx)
 				if err != nil {
-					return nil, err
+					return nil, bp.rollback(created, err, ctx)
+				}
+				if !existingUser {
+					created = append(created, dao.DbResource{Kind: common.UserKind, Name: username})
 				}
+				usersByRoleType[AdminRoleType] = append(usersByRoleType[AdminRoleType], username)
 				resources = append(resources, securityResources...)
 			}
 			// Possibly need to move additionalRoles and response logic into separate methods for v2
@@ -388,8 +521,10 @@ func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx con
 					additionalUsername, additionalPassword, securityResources, err =
 						bp.CreateUserByPrefix(additionalUsername, additionalPassword, dbName, roleType, ctx)
 					if err != nil {
-						return nil, err
+						return nil, bp.rollback(created, err, ctx)
 					}
+					created = append(created, dao.DbResource{Kind: common.UserKind, Name: additionalUsername})
+					usersByRoleType[roleType] = append(usersByRoleType[roleType], additionalUsername)
 					connectionProperties := bp.GetExtendedConnectionProperties(indexName, additionalUsername,
 						additionalPassword, prefix, roleType)
 					connections = append(connections, connectionProperties)
@@ -399,16 +534,23 @@ func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx con
 		}
 	}
 
+	if len(requestOnCreateDb.Settings.AdditionalPermissions) > 0 {
+		roleResources, err := bp.createDatabaseRoles(prefix, requestOnCreateDb.Settings.AdditionalPermissions,
+			usersByRoleType, ctx)
+		created = append(created, roleResources...)
+		if err != nil {
+			return nil, bp.rollback(created, err, ctx)
+		}
+		resources = append(resources, roleResources...)
+	}
+
 	metadataID := prefix
 	if indexName != "" {
 		metadataID = indexName
 	}
 	_, err = bp.CreateMetadata(metadataID, requestOnCreateDb.Metadata, ctx)
 	if err != nil {
-		if indexName != "" {
-			_ = bp.deleteDatabase(indexName, ctx)
-		}
-		return nil, err
+		return nil, bp.rollback(created, err, ctx)
 	}
 	resources = append(resources, dao.DbResource{Kind: common.MetadataKind, Name: metadataID})
 
@@ -428,9 +570,44 @@ func (bp BaseProvider) createDatabase(requestOnCreateDb DbCreateRequest, ctx con
 	return result, nil
 }
 
+// rollback removes resources created during failed database creation and wraps the initial error with rollback results.
+func (bp BaseProvider) rollback(created []dao.DbResource, cause error, ctx context.Context) error {
+	if len(created) == 0 {
+		return cause
+	}
+	logger.WarnContext(ctx, fmt.Sprintf("Database creation failed, rolling back created resources: %v", created))
+	rolledBack := bp.deleteResources(created, ctx)
+	failed := getResourcesWithFailedStatus(rolledBack)
+	if len(failed) > 0 {
+		logger.ErrorContext(ctx, fmt.Sprintf("Failed to roll back the following resources: %v", failed))
+	} else {
+		logger.InfoContext(ctx, "Created resources are rolled back")
+	}
+	return &RollbackError{Cause: cause, Resources: rolledBack}
+}
+
+func (e *RollbackError) Error() string {
+	failed := getResourcesWithFailedStatus(e.Resources)
+	if len(failed) > 0 {
+		return fmt.Sprintf("%v; rollback failed for resources: %v", e.Cause, failed)
+	}
+	return fmt.Sprintf("%v; created resources are rolled back", e.Cause)
+}
+
+func (e *RollbackError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *RollbackError) Response() RollbackResponse {
+	return RollbackResponse{
+		RollbackSucceeded:   len(getResourcesWithFailedStatus(e.Resources)) == 0,
+		RolledBackResources: e.Resources,
+	}
+}
+
 func checkForbiddenSymbolPrefix(namePrefix string) error {
 	if strings.HasPrefix(namePrefix, ".") || strings.Contains(namePrefix, "*") {
-		return errors.New("prefix contains forbidden symbols")
+		return common.NewBadRequestError(common.InvalidPrefixCode, errors.New("prefix contains forbidden symbols"))
 	}
 	return nil
 }
@@ -448,6 +625,12 @@ func (bp BaseProvider) createIndex(requestOnCreateDb DbCreateRequest, prefix str
 		logger.ErrorContext(ctx, fmt.Sprintf("Error occurred during creating '%s' index", indexName), slog.Any("error", err))
 		return indexName, err
 	}
+	defer indexResponse.Body.Close()
+	if indexResponse.IsError() {
+		body, _ := io.ReadAll(indexResponse.Body)
+		return indexName, fmt.Errorf("'%s' index cannot be created because of error: [%d] %s",
+			indexName, indexResponse.StatusCode, string(body))
+	}
 	logger.InfoContext(ctx, fmt.Sprintf("Index with name '%s' is created: %s", indexName, indexResponse.Body))
 	return indexName, nil
 }
@@ -503,6 +686,58 @@ func (bp BaseProvider) listDatabases() ([]string, error) {
 	return indices, nil
 }
 
+// listLogicalDatabases returns databases known by metadata documents and `resource_prefix` attributes of users
+// with indices grouped under the longest database name they start with followed by resourcePrefixDelimiter. Index which
+// name is equal to the database name belongs to that database.
+func (bp BaseProvider) listLogicalDatabases(ctx context.Context) ([]LogicalDatabase, error) {
+	databases := make(map[string]*LogicalDatabase)
+	metadata, err := bp.listMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for name := range metadata {
+		databases[name] = &LogicalDatabase{Name: name, Indices: []string{}}
+	}
+	users, err := bp.getUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		prefix := user.Attributes[resourcePrefixAttributeName]
+		if _, ok := databases[prefix]; prefix != "" && !ok {
+			databases[prefix] = &LogicalDatabase{Name: prefix, Indices: []string{}}
+		}
+	}
+	prefixes := make(map[string]struct{}, len(databases))
+	for name := range databases {
+		prefixes[name] = struct{}{}
+	}
+	indices, err := bp.getIndicesByPattern("", ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indices {
+		if strings.HasPrefix(index.Index, ".") || index.Index == DbaasMetadata {
+			continue
+		}
+		owner, ok := databases[index.Index]
+		if !ok {
+			owner, ok = databases[databaseOwner(index.Index, "", prefixes)]
+		}
+		if ok {
+			owner.Indices = append(owner.Indices, index.Index)
+		}
+	}
+	result := make([]LogicalDatabase, 0, len(databases))
+	for _, database := range databases {
+		result = append(result, *database)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
 func (bp BaseProvider) deleteDatabase(name string, ctx context.Context) error {
 	indicesDeleteRequest := opensearchapi.IndicesDeleteRequest{
 		Index: []string{name},
@@ -535,6 +770,26 @@ func (bp BaseProvider) GetMetadata(indexName string, ctx context.Context) (map[s
 	return response.Source, nil
 }
 
+// listMetadata returns all documents of metadata index by their identifiers
+func (bp BaseProvider) listMetadata(ctx context.Context) (map[string]map[string]interface{}, error) {
+	size := metadataSearchSize
+	searchRequest := opensearchapi.SearchRequest{
+		Index: []string{DbaasMetadata},
+		Size:  &size,
+	}
+	var response metadataSearchResponse
+	err := common.DoRequest(searchRequest, bp.opensearch.Client, &response, ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("Error occurred during search in '%s' index", DbaasMetadata), slog.Any("error", err))
+		return nil, err
+	}
+	result := make(map[string]map[string]interface{}, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		result[hit.ID] = hit.Source
+	}
+	return result, nil
+}
+
 func (bp BaseProvider) CreateMetadata(identifier string, metadata map[string]interface{}, ctx context.Context) (string, error) {
 	logger.InfoContext(ctx, fmt.Sprintf("Insert metadata to '%s' index by '%s' identifier", DbaasMetadata, identifier))
 	if metadata == nil {
@@ -549,6 +804,8 @@ func (bp BaseProvider) CreateMetadata(identifier string, metadata map[string]int
 		Index:      DbaasMetadata,
 		DocumentID: identifier,
 		Body:       body,
+		// metadata should be visible for search by classifier of the next request once the database is created
+		Refresh: "wait_for",
 	}
 	response, err := indexRequest.Do(context.Background(), bp.opensearch.Client)
 	if err != nil {
@@ -581,6 +838,10 @@ func (bp BaseProvider) updateMetadata(indexName string, metadata map[string]inte
 			return "", err
 		}
 		defer response.Body.Close()
+		if response.StatusCode == http.StatusNotFound {
+			return "", common.NewNotFoundError(common.NotFoundCode,
+				fmt.Errorf("metadata for '%s' database is not found", indexName))
+		}
 		responseBody, err := io.ReadAll(response.Body)
 		if err != nil {
 			return "", err
@@ -733,7 +994,7 @@ func (bp BaseProvider) deleteAlias(alias string, ctx context.Context) error {
 
 func (bp BaseProvider) getConnectionProperties(dbName string, username string, password string) common.ConnectionProperties {
 	url := fmt.Sprintf("%s://%s:%d/%s", bp.opensearch.Protocol, bp.opensearch.Host, bp.opensearch.Port, dbName)
-	return common.ConnectionProperties{
+	connectionProperties := common.ConnectionProperties{
 		DbName:   dbName,
 		Host:     bp.opensearch.Host,
 		Port:     bp.opensearch.Port,
@@ -741,6 +1002,10 @@ func (bp BaseProvider) getConnectionProperties(dbName string, username string, p
 		Username: username,
 		Password: password,
 	}
+	if bp.opensearch.AdvertiseNodes {
+		connectionProperties.Nodes = bp.opensearch.Nodes
+	}
+	return connectionProperties
 }
 
 func (bp BaseProvider) IsOpenSearchTlsEnabled() bool {
@@ -759,67 +1024,257 @@ func (bp BaseProvider) GetExtendedConnectionProperties(dbName string, username s
 	return connectionProperties
 }
 
+// resourceKindsDeletionOrder is the order in which resources of different kinds are deleted
+var resourceKindsDeletionOrder = []string{
+	common.RoleKind,
+	common.UserKind,
+	common.IndexKind,
+	common.MetadataKind,
+	common.TemplateKind,
+	common.IndexTemplateKind,
+	common.AliasKind,
+}
+
 func (bp BaseProvider) deleteResources(resources []dao.DbResource, ctx context.Context) []dao.DbResource {
 	var deletedResources []dao.DbResource
 
-	resources = append(resources, bp.processResourcePrefixKind(resources, ctx)...)
-
-	users := bp.deleteResourcesByKind(resources, common.UserKind)
-	deletedResources = append(deletedResources, users...)
-
-	databases := bp.deleteResourcesByKind(resources, common.IndexKind)
-	deletedResources = append(deletedResources, databases...)
-
-	metadata := bp.deleteResourcesByKind(resources, common.MetadataKind)
-	deletedResources = append(deletedResources, metadata...)
+	prefixResources, err := bp.processResourcePrefixKind(resources, ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to expand resource prefixes, resources by prefixes are not deleted", slog.Any("error", err))
+		for _, resource := range resources {
+			if resource.Kind == common.ResourcePrefixKind {
+				deletedResources = append(deletedResources, *getResourceDeletionFailedStatus(resource, err))
+			}
+		}
+	}
+	resources = append(resources, prefixResources...)
 
-	templates := bp.deleteResourcesByKind(resources, common.TemplateKind)
-	deletedResources = append(deletedResources, templates...)
+	for _, kind := range resourceKindsDeletionOrder {
+		deletedResources = append(deletedResources, bp.deleteResourcesByKind(resources, kind)...)
+	}
 
-	indexTemplates := bp.deleteResourcesByKind(resources, common.IndexTemplateKind)
-	deletedResources = append(deletedResources, indexTemplates...)
+	return deletedResources
+}
 
-	aliases := bp.deleteResourcesByKind(resources, common.AliasKind)
-	deletedResources = append(deletedResources, aliases...)
+// resolveResources returns concrete resources which are deleted by deleteResources for the same request
+// without deleting them. Resource prefixes are expanded, wildcards are resolved against the cluster and
+// resources which do not exist are skipped.
+func (bp BaseProvider) resolveResources(resources []dao.DbResource, ctx context.Context) ([]dao.DbResource, error) {
+	prefixResources, err := bp.processResourcePrefixKind(resources, ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, prefixResources...)
+	resolvedResources := make([]dao.DbResource, 0)
+	found := make(map[dao.DbResource]struct{})
+	for _, kind := range resourceKindsDeletionOrder {
+		for _, resource := range resources {
+			if resource.Kind != kind {
+				continue
+			}
+			names, err := bp.resolveResource(resource, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve '%s' %s: %w", resource.Name, resource.Kind, err)
+			}
+			for _, name := range names {
+				resolved := dao.DbResource{Kind: kind, Name: name}
+				if _, ok := found[resolved]; !ok {
+					found[resolved] = struct{}{}
+					resolvedResources = append(resolvedResources, resolved)
+				}
+			}
+		}
+	}
+	return resolvedResources, nil
+}
 
-	return deletedResources
+// resolveResource returns names of existing resources matching the name of the given resource
+func (bp BaseProvider) resolveResource(resource dao.DbResource, ctx context.Context) ([]string, error) {
+	switch resource.Kind {
+	case common.IndexKind:
+		if !strings.Contains(resource.Name, "*") {
+			database, err := bp.getDatabase(resource.Name)
+			if err != nil || database == nil {
+				return nil, err
+			}
+			return []string{resource.Name}, nil
+		}
+		indices, err := bp.getIndicesByPattern(resource.Name, ctx)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(indices))
+		for _, index := range indices {
+			names = append(names, index.Index)
+		}
+		return names, nil
+	case common.MetadataKind:
+		metadata, err := bp.GetMetadata(resource.Name, ctx)
+		if err != nil || metadata == nil {
+			return nil, err
+		}
+		return []string{resource.Name}, nil
+	case common.UserKind:
+		user, err := bp.GetUser(resource.Name)
+		if err != nil || user == nil {
+			return nil, err
+		}
+		return []string{resource.Name}, nil
+	case common.RoleKind:
+		role, err := bp.GetRole(resource.Name)
+		if err != nil || role == nil {
+			return nil, err
+		}
+		if _, ok := databaseRolePrefix(resource.Name, *role); !ok {
+			return nil, nil
+		}
+		return []string{resource.Name}, nil
+	case common.TemplateKind:
+		return bp.getTemplatesByPattern(resource.Name, ctx)
+	case common.IndexTemplateKind:
+		return bp.getIndexTemplatesByPattern(resource.Name, ctx)
+	case common.AliasKind:
+		return bp.getAliasesByPattern(resource.Name, ctx)
+	}
+	return nil, nil
 }
 
-func (bp BaseProvider) processResourcePrefixKind(resources []dao.DbResource, ctx context.Context) []dao.DbResource {
+// processResourcePrefixKind expands resource prefixes to concrete resources of databases. Only resources whose names
+// start with the prefix followed by resourcePrefixDelimiter are considered, and each of them must be attributed to
+// the dropped prefix rather than to a longer prefix of another database known by metadata documents or users,
+// so dropping `app` does not touch resources of `app2` or `app_x` databases.
+func (bp BaseProvider) processResourcePrefixKind(resources []dao.DbResource, ctx context.Context) ([]dao.DbResource, error) {
 	var additionalResources []dao.DbResource
+	var prefixes map[string]struct{}
+	var users map[string]User
 	for _, resource := range resources {
-		if resource.Kind == common.ResourcePrefixKind {
-			namePattern := fmt.Sprintf("%s*", resource.Name)
-			if bp.ApiVersion == common.ApiV1 {
-				additionalResources = append(additionalResources, []dao.DbResource{
-					{Kind: common.UserKind, Name: resource.Name},
-					{Kind: common.IndexKind, Name: namePattern},
-					{Kind: common.MetadataKind, Name: resource.Name},
-					{Kind: common.TemplateKind, Name: namePattern},
-					{Kind: common.IndexTemplateKind, Name: namePattern},
-					{Kind: common.AliasKind, Name: namePattern},
-				}...)
-			} else if bp.ApiVersion == common.ApiV2 {
-				additionalResources = append(additionalResources, []dao.DbResource{
-					{Kind: common.IndexKind, Name: namePattern},
-					{Kind: common.MetadataKind, Name: resource.Name},
-					{Kind: common.TemplateKind, Name: namePattern},
-					{Kind: common.IndexTemplateKind, Name: namePattern},
-					{Kind: common.AliasKind, Name: namePattern},
-				}...)
-				users, err := bp.getUsersByPrefix(resource.Name)
-				if err != nil {
-					logger.ErrorContext(ctx, fmt.Sprintf("Failed to receive users with prefix %s ", resource.Name), slog.Any("error", err))
-				} else {
-					for _, user := range users {
-						additionalResources = append(additionalResources,
-							dao.DbResource{Kind: common.UserKind, Name: user})
-					}
+		if resource.Kind != common.ResourcePrefixKind {
+			continue
+		}
+		if prefixes == nil {
+			var err error
+			if prefixes, users, err = bp.getDatabasePrefixes(ctx); err != nil {
+				return nil, fmt.Errorf("failed to receive owners of resources to delete: %w", err)
+			}
+		}
+		prefix := resource.Name
+		additionalResources = append(additionalResources, dao.DbResource{Kind: common.MetadataKind, Name: prefix})
+		if bp.ApiVersion == common.ApiV1 {
+			additionalResources = append(additionalResources, dao.DbResource{Kind: common.UserKind, Name: prefix})
+		} else if bp.ApiVersion == common.ApiV2 {
+			for _, username := range usersOfPrefix(users, prefix, prefixes) {
+				additionalResources = append(additionalResources, dao.DbResource{Kind: common.UserKind, Name: username})
+			}
+		}
+		namePattern := prefix + resourcePrefixDelimiter + "*"
+		indices, err := bp.getIndicesByPattern(namePattern, ctx)
+		if err != nil {
+			return nil, err
+		}
+		indexNames := make([]string, 0, len(indices))
+		for _, index := range indices {
+			indexNames = append(indexNames, index.Index)
+		}
+		templates, err := bp.getTemplatesByPattern(namePattern, ctx)
+		if err != nil {
+			return nil, err
+		}
+		indexTemplates, err := bp.getIndexTemplatesByPattern(namePattern, ctx)
+		if err != nil {
+			return nil, err
+		}
+		aliases, err := bp.getAliasesByPattern(namePattern, ctx)
+		if err != nil {
+			return nil, err
+		}
+		roles, err := bp.getDatabaseRoles(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			additionalResources = append(additionalResources, dao.DbResource{Kind: common.RoleKind, Name: role})
+		}
+		for _, found := range []struct {
+			kind  string
+			names []string
+		}{
+			{common.IndexKind, indexNames},
+			{common.TemplateKind, templates},
+			{common.IndexTemplateKind, indexTemplates},
+			{common.AliasKind, aliases},
+		} {
+			kind := found.kind
+			for _, name := range found.names {
+				if owner := databaseOwner(name, prefix, prefixes); owner != prefix {
+					logger.WarnContext(ctx, fmt.Sprintf("'%s' %s belongs to '%s' database, it is not deleted with '%s' prefix",
+						name, kind, owner, prefix))
+					continue
 				}
+				additionalResources = append(additionalResources, dao.DbResource{Kind: kind, Name: name})
 			}
 		}
 	}
-	return additionalResources
+	return additionalResources, nil
+}
+
+// getDatabasePrefixes returns prefixes of databases known by metadata documents and `resource_prefix` attributes
+// of users, users are returned as well to not request them twice.
+func (bp BaseProvider) getDatabasePrefixes(ctx context.Context) (map[string]struct{}, map[string]User, error) {
+	metadata, err := bp.listMetadata(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	users, err := bp.getUsers()
+	if err != nil {
+		return nil, nil, err
+	}
+	return databasePrefixes(metadata, users), users, nil
+}
+
+// databasePrefixes returns identifiers of metadata documents and `resource_prefix` attributes of users
+func databasePrefixes(metadata map[string]map[string]interface{}, users map[string]User) map[string]struct{} {
+	prefixes := make(map[string]struct{}, len(metadata))
+	for name := range metadata {
+		prefixes[name] = struct{}{}
+	}
+	for _, user := range users {
+		if prefix := user.Attributes[resourcePrefixAttributeName]; prefix != "" {
+			prefixes[prefix] = struct{}{}
+		}
+	}
+	return prefixes
+}
+
+// databaseOwner returns the longest of known prefixes and the given one which the name starts with followed
+// by resourcePrefixDelimiter, the name is not attributed to any database if the result is empty.
+func databaseOwner(name string, prefix string, prefixes map[string]struct{}) string {
+	owner := ""
+	if strings.HasPrefix(name, prefix+resourcePrefixDelimiter) {
+		owner = prefix
+	}
+	for known := range prefixes {
+		if len(known) > len(owner) && strings.HasPrefix(name, known+resourcePrefixDelimiter) {
+			owner = known
+		}
+	}
+	return owner
+}
+
+// usersOfPrefix returns sorted names of users with the given `resource_prefix` attribute. Users without the attribute
+// belong to the prefix if their names are attributed to it.
+func usersOfPrefix(users map[string]User, prefix string, prefixes map[string]struct{}) []string {
+	var usernames []string
+	for name, user := range users {
+		owner := user.Attributes[resourcePrefixAttributeName]
+		if owner == "" {
+			owner = databaseOwner(name, prefix, prefixes)
+		}
+		if owner == prefix {
+			usernames = append(usernames, name)
+		}
+	}
+	sort.Strings(usernames)
+	return usernames
 }
 
 func (bp BaseProvider) deleteResourcesByKind(resources []dao.DbResource, kind string) []dao.DbResource {
@@ -882,6 +1337,26 @@ func (bp BaseProvider) deleteResource(resource dao.DbResource, ctx context.Conte
 			logger.ErrorContext(ctx, fmt.Sprintf("Failed to delete '%s' user", resource.Name), slog.Any("error", err))
 			return getResourceDeletionFailedStatus(resource, err)
 		}
+	} else if resource.Kind == common.RoleKind {
+		role, err := bp.GetRole(resource.Name)
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to receive '%s' role information", resource.Name), slog.Any("error", err))
+			return getResourceDeletionFailedStatus(resource, err)
+		}
+		if role == nil {
+			logger.InfoContext(ctx, fmt.Sprintf("'%s' role does not exist, skip deletion", resource.Name))
+			return getResourceDeletionSuccessStatus(resource)
+		}
+		if _, ok := databaseRolePrefix(resource.Name, *role); !ok {
+			err = fmt.Errorf("'%s' role is not created for a database, it cannot be deleted", resource.Name)
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to delete '%s' role", resource.Name), slog.Any("error", err))
+			return getResourceDeletionFailedStatus(resource, err)
+		}
+		err = bp.deleteDatabaseRole(resource.Name, ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to delete '%s' role", resource.Name), slog.Any("error", err))
+			return getResourceDeletionFailedStatus(resource, err)
+		}
 	} else if resource.Kind == common.TemplateKind {
 		template, err := bp.getTemplate(resource.Name)
 		if err != nil {
@@ -963,7 +1438,7 @@ func buildIndexName(dbName string, prefix string) string {
 	if dbName == "" {
 		dbName = common.GetUUID()
 	}
-	indexName = fmt.Sprintf("%s_%s", prefix, dbName)
+	indexName = prefix + resourcePrefixDelimiter + dbName
 	return indexName
 }
 
@@ -978,3 +1453,22 @@ func getIndexSettings(createDbRequest *DbCreateRequest, ctx context.Context) str
 	}
 	return ""
 }
+
+// runPeriodically calls the function at once and then with the given interval until shutdown,
+// shutdown waits for the call in progress.
+func (bp *BaseProvider) runPeriodically(interval time.Duration, function func()) {
+	bp.recoveryGroup.Add(1)
+	go func() {
+		defer bp.recoveryGroup.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			function()
+			select {
+			case <-bp.recoveryStop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
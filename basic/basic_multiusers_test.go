@@ -15,13 +15,20 @@
 package basic
 
 import (
+	"context"
 	"fmt"
 	"github.com/Netcracker/dbaas-opensearch-adapter/cluster"
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
 	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var (
@@ -36,6 +43,7 @@ var (
 		mutex:             &sync.Mutex{},
 		passwordGenerator: NewPasswordGenerator(),
 		ApiVersion:        common.ApiV2,
+		classifierLocks:   newKeyedMutex(),
 	}
 )
 
@@ -174,3 +182,122 @@ func TestUpdateMultiUserPassword(t *testing.T) {
 	}
 	assert.ElementsMatch(t, user.Resources, expectedResources)
 }
+
+func TestCreateMultiUsersWithExistingClassifier(t *testing.T) {
+	requestOnCreateDb := DbCreateRequest{
+		Metadata: map[string]interface{}{
+			"classifier": map[string]interface{}{
+				"microserviceName": "stub-service",
+				"namespace":        "stub-namespace",
+			},
+		},
+		Settings: Settings{
+			ResourcePrefix: true,
+		},
+	}
+	r, err := bp.createDatabase(requestOnCreateDb, ctx)
+	assert.Empty(t, err)
+	response := r.(DbCreateResponseMultiUser)
+	expectedUsers := map[string]string{
+		ReadOnlyRoleType: "stubprefix_9f1e7c3a2b4d4f6e8a0c5b7d9e1f3a5c",
+		DmlRoleType:      "stubprefix_b2d4f6a8c0e24a6c8e0a2c4e6a8c0e2a",
+		AdminRoleType:    "stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1",
+		IsmRoleType:      "stubprefix_c3e5a7c9e1f34b5d7f9b1d3f5b7d9f1b",
+	}
+	assert.Len(t, response.ConnectionProperties, len(expectedUsers))
+	for _, connection := range response.ConnectionProperties {
+		assert.Equal(t, expectedUsers[connection.Role], connection.Username)
+		assert.Equal(t, "stubprefix", connection.ResourcePrefix)
+		assert.Empty(t, connection.Password)
+	}
+	assert.Contains(t, response.Resources, dao.DbResource{Kind: common.MetadataKind, Name: "stubprefix"})
+}
+
+func TestShutdownWaitsForUsersRecovery(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	provider.ApiVersion = common.ApiV2
+	body := `{"connectionProperties":[{"username":"recovereduser","password":"password","resourcePrefix":"recoveredprefix","role":"admin"}]}`
+	recorder := httptest.NewRecorder()
+	provider.RecoverUsersHandler()(recorder, httptest.NewRequest(http.MethodPost, "/users/restore-password", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := provider.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, []string{RecoveryDoneState, RecoveryFailedState}, provider.getRecoveryState())
+
+	recorder = httptest.NewRecorder()
+	provider.RecoverUsersHandler()(recorder, httptest.NewRequest(http.MethodPost, "/users/restore-password", strings.NewReader(body)))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), common.ShuttingDownCode)
+}
+
+func TestShutdownWaitsForPeriodicProcesses(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+	provider.runPeriodically(time.Hour, func() {
+		close(started)
+		<-release
+		finished.Store(true)
+	})
+	<-started
+
+	shortCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, provider.Shutdown(shortCtx))
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, provider.Shutdown(ctx))
+	assert.True(t, finished.Load())
+}
+
+// collidingPrefixesClient returns resources of `app`, `app_x` and `app2` databases
+type collidingPrefixesClient struct {
+	*common.ClientStub
+}
+
+func (c collidingPrefixesClient) Perform(req *http.Request) (*http.Response, error) {
+	var body string
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/_cat/indices"):
+		body = `[{"index":"app_orders","status":"open"},{"index":"app_x_orders","status":"open"}]`
+	case strings.HasPrefix(req.URL.Path, "/dbaas_opensearch_metadata/_search"):
+		body = `{"hits":{"hits":[{"_id":"app","_source":{}},{"_id":"app_x","_source":{}}]}}`
+	case req.URL.Path == "/_plugins/_security/api/internalusers" && req.Method == http.MethodGet:
+		body = `{"app_1":{"attributes":{"resource_prefix":"app"}},"app_legacy":{"attributes":{}},"app_x_1":{"attributes":{"resource_prefix":"app_x"}},"app2_1":{"attributes":{"resource_prefix":"app2"}},"app_x_legacy":{"attributes":{}}}`
+	default:
+		return c.ClientStub.Perform(req)
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestProcessResourcePrefixWithCollidingPrefixes(t *testing.T) {
+	provider := bp
+	provider.opensearch = &cluster.Opensearch{Client: collidingPrefixesClient{ClientStub: common.NewClient()}}
+	resources, err := provider.processResourcePrefixKind([]dao.DbResource{{Kind: common.ResourcePrefixKind, Name: "app"}}, ctx)
+	assert.NoError(t, err)
+	expectedResources := []dao.DbResource{
+		{Kind: common.MetadataKind, Name: "app"},
+		{Kind: common.UserKind, Name: "app_1"},
+		{Kind: common.UserKind, Name: "app_legacy"},
+		{Kind: common.IndexKind, Name: "app_orders"},
+		{Kind: common.TemplateKind, Name: "app_template"},
+		{Kind: common.IndexTemplateKind, Name: "app_index_template"},
+		{Kind: common.AliasKind, Name: "app_alias"},
+	}
+	assert.Equal(t, expectedResources, resources)
+}
+
+func TestDatabaseOwner(t *testing.T) {
+	prefixes := map[string]struct{}{"app": {}, "app_x": {}, "app2": {}}
+	assert.Equal(t, "app", databaseOwner("app_orders", "app", prefixes))
+	assert.Equal(t, "app_x", databaseOwner("app_x_orders", "app", prefixes))
+	assert.Equal(t, "app2", databaseOwner("app2_orders", "app", prefixes))
+	assert.Equal(t, "", databaseOwner("application", "app", prefixes))
+	assert.Equal(t, "other", databaseOwner("other_orders", "other", prefixes))
+}
@@ -15,15 +15,22 @@
 package basic
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/Netcracker/dbaas-opensearch-adapter/cluster"
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
 	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 var baseProvider BaseProvider
@@ -49,6 +56,7 @@ func setup() {
 		mutex:             &sync.Mutex{},
 		passwordGenerator: NewPasswordGenerator(),
 		ApiVersion:        common.ApiV1,
+		classifierLocks:   newKeyedMutex(),
 	}
 	ctx = context.WithValue(context.Background(), common.RequestIdKey, common.GenerateUUID())
 }
@@ -57,6 +65,127 @@ func shutdown() {
 	baseProvider.opensearch.Client = nil
 }
 
+const (
+	rolesPath        = "/_plugins/_security/api/roles/"
+	rolesMappingPath = "/_plugins/_security/api/rolesmapping/"
+)
+
+// recordingClient remembers requests sent to the stub, users batches and saved tombstones. Responses can be replaced
+// by "METHOD path" or by path, roles and role mappings are kept in the client and returned back if stubSecurity is called.
+type recordingClient struct {
+	*common.ClientStub
+	requests   []string
+	patches    [][]Change
+	tombstones []Tombstone
+	responses  map[string]string
+	roles      map[string]Role
+	mappings   map[string]RoleMapping
+	// puts is the number of roles and role mappings written to the client
+	puts int
+}
+
+// newTestProvider returns v2 provider which sends requests to the recording stub
+func newTestProvider() (BaseProvider, *recordingClient) {
+	client := &recordingClient{ClientStub: common.NewClient(), responses: map[string]string{}}
+	provider := BaseProvider{
+		opensearch: &cluster.Opensearch{
+			Host:     "localhost",
+			Port:     9200,
+			Protocol: common.Http,
+			Client:   client,
+		},
+		mutex:             &sync.Mutex{},
+		passwordGenerator: NewPasswordGenerator(),
+		ApiVersion:        common.ApiV2,
+		classifierLocks:   newKeyedMutex(),
+	}
+	return provider, client
+}
+
+func (c *recordingClient) stubSecurity() {
+	c.roles = map[string]Role{}
+	c.mappings = map[string]RoleMapping{}
+}
+
+func (c *recordingClient) Perform(req *http.Request) (*http.Response, error) {
+	c.requests = append(c.requests, req.Method+" "+req.URL.Path)
+	var body []byte
+	if req.Body != nil && (req.Method == http.MethodPut || req.Method == http.MethodPost || req.Method == http.MethodPatch) {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		if strings.HasPrefix(req.URL.Path, "/"+TombstonesIndex+"/_doc/") {
+			var tombstone Tombstone
+			if err = json.Unmarshal(body, &tombstone); err != nil {
+				return nil, err
+			}
+			c.tombstones = append(c.tombstones, tombstone)
+		} else if req.Method == http.MethodPatch {
+			var changes []Change
+			if err = json.Unmarshal(body, &changes); err != nil {
+				return nil, err
+			}
+			c.patches = append(c.patches, changes)
+		}
+	}
+	if response, ok := c.responses[req.Method+" "+req.URL.Path]; ok {
+		return stubResponse(http.StatusOK, response), nil
+	}
+	if response, ok := c.responses[req.URL.Path]; ok {
+		return stubResponse(http.StatusOK, response), nil
+	}
+	isRole := strings.HasPrefix(req.URL.Path, rolesPath)
+	if c.roles == nil || !isRole && !strings.HasPrefix(req.URL.Path, rolesMappingPath) {
+		return c.ClientStub.Perform(req)
+	}
+	return c.performSecurity(req, isRole, body)
+}
+
+func (c *recordingClient) performSecurity(req *http.Request, isRole bool, body []byte) (*http.Response, error) {
+	name := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, rolesPath), rolesMappingPath)
+	switch req.Method {
+	case http.MethodPut:
+		c.puts++
+		var err error
+		if isRole {
+			var role Role
+			err = json.Unmarshal(body, &role)
+			c.roles[name] = role
+		} else {
+			var mapping RoleMapping
+			err = json.Unmarshal(body, &mapping)
+			c.mappings[name] = mapping
+		}
+		if err != nil {
+			return nil, err
+		}
+		return stubResponse(http.StatusOK, `{"status":"OK"}`), nil
+	case http.MethodGet:
+		var found bool
+		var value interface{}
+		if isRole {
+			value, found = c.roles[name]
+		} else {
+			value, found = c.mappings[name]
+		}
+		if !found {
+			return stubResponse(http.StatusNotFound, `{"status":"NOT_FOUND"}`), nil
+		}
+		content, err := json.Marshal(map[string]interface{}{name: value})
+		if err != nil {
+			return nil, err
+		}
+		return stubResponse(http.StatusOK, string(content)), nil
+	}
+	return stubResponse(http.StatusOK, ""), nil
+}
+
+func stubResponse(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewBufferString(body))}
+}
+
 func TestGetIndex(t *testing.T) {
 	indexName := "dbaas_metadata"
 	index, err := baseProvider.getDatabase(indexName)
@@ -424,15 +553,64 @@ func TestDeleteResourcesByPrefix(t *testing.T) {
 	deletedResources := baseProvider.deleteResources(resources, context.Background())
 	expectedDeletedResources := []dao.DbResource{
 		{Kind: common.UserKind, Name: "test", Status: DeletedStatus, ErrorMessage: ""},
-		{Kind: common.IndexKind, Name: "test*", Status: DeletedStatus, ErrorMessage: ""},
+		{Kind: common.IndexKind, Name: "test_customers", Status: DeletedStatus, ErrorMessage: ""},
+		{Kind: common.IndexKind, Name: "test_orders", Status: DeletedStatus, ErrorMessage: ""},
 		{Kind: common.MetadataKind, Name: "test", Status: DeletedStatus, ErrorMessage: ""},
-		{Kind: common.TemplateKind, Name: "test*", Status: DeletedStatus, ErrorMessage: ""},
-		{Kind: common.IndexTemplateKind, Name: "test*", Status: DeletedStatus, ErrorMessage: ""},
-		{Kind: common.AliasKind, Name: "test*", Status: DeletedStatus, ErrorMessage: ""},
+		{Kind: common.TemplateKind, Name: "test_template", Status: DeletedStatus, ErrorMessage: ""},
+		{Kind: common.IndexTemplateKind, Name: "test_index_template", Status: DeletedStatus, ErrorMessage: ""},
+		{Kind: common.AliasKind, Name: "test_alias", Status: DeletedStatus, ErrorMessage: ""},
 	}
 	assert.Equal(t, expectedDeletedResources, deletedResources)
 }
 
+func TestResolveResourcesByPrefix(t *testing.T) {
+	resources := []dao.DbResource{
+		{Kind: common.ResourcePrefixKind, Name: "test"},
+		{Kind: common.IndexKind, Name: "test_orders"},
+	}
+	resolvedResources, err := baseProvider.resolveResources(resources, context.Background())
+	assert.Nil(t, err)
+	expectedResources := []dao.DbResource{
+		{Kind: common.UserKind, Name: "test"},
+		{Kind: common.IndexKind, Name: "test_orders"},
+		{Kind: common.IndexKind, Name: "test_customers"},
+		{Kind: common.MetadataKind, Name: "test"},
+		{Kind: common.TemplateKind, Name: "test_template"},
+		{Kind: common.IndexTemplateKind, Name: "test_index_template"},
+		{Kind: common.AliasKind, Name: "test_alias"},
+	}
+	assert.Equal(t, expectedResources, resolvedResources)
+}
+
+func TestBulkDropResourcesDryRun(t *testing.T) {
+	provider := baseProvider
+	provider.opensearch = &cluster.Opensearch{Client: readOnlyClient{ClientStub: common.NewClient()}}
+	body := `[{"kind":"resourcePrefix","name":"test"},{"kind":"user","name":"` + common.FailingUsername + `"}]`
+	request := httptest.NewRequest(http.MethodPost, "/resources/bulk-drop?dryRun=true", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	provider.BulkDropResourceHandler()(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var resources []dao.DbResource
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &resources))
+	assert.Len(t, resources, 7)
+	for _, resource := range resources {
+		assert.Empty(t, resource.Status)
+		assert.NotEqual(t, common.FailingUsername, resource.Name)
+	}
+}
+
+// readOnlyClient fails requests which modify the cluster
+type readOnlyClient struct {
+	*common.ClientStub
+}
+
+func (c readOnlyClient) Perform(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead && !strings.HasSuffix(req.URL.Path, "/_search") {
+		return nil, fmt.Errorf("unexpected '%s' request to '%s' path", req.Method, req.URL.Path)
+	}
+	return c.ClientStub.Perform(req)
+}
+
 func TestCreateUserWithMicroserviceAndNamespaceInMetadata(t *testing.T) {
 	requestOnCreateDb := DbCreateRequest{
 		Metadata: map[string]interface{}{
@@ -524,3 +702,265 @@ func TestCreateUserWithNamespaceInMetadata(t *testing.T) {
 	}
 	assert.ElementsMatch(t, response.Resources, expectedResources)
 }
+
+func TestDescribeDatabases(t *testing.T) {
+	prefix := "stubprefix"
+	descriptions, err := baseProvider.describeDatabases([]string{prefix}, ctx)
+	assert.Empty(t, err)
+	assert.Len(t, descriptions, 1)
+	description := descriptions[prefix]
+	assert.Equal(t, "check", description.Metadata["text"])
+	expectedIndices := []IndexDescription{
+		{Name: "stubprefix_customers", DocsCount: 20, StoreSize: 10240},
+		{Name: "stubprefix_orders", DocsCount: 100, StoreSize: 204800},
+	}
+	assert.Equal(t, expectedIndices, description.Indices)
+	assert.Equal(t, []string{"stubprefix_alias"}, description.Aliases)
+	assert.Equal(t, []string{"stubprefix_template"}, description.Templates)
+	assert.Equal(t, []string{"stubprefix_index_template"}, description.IndexTemplates)
+	expectedUsers := []string{
+		"stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1",
+		"stubprefix_9f1e7c3a2b4d4f6e8a0c5b7d9e1f3a5c",
+		"stubprefix_b2d4f6a8c0e24a6c8e0a2c4e6a8c0e2a",
+		"stubprefix_c3e5a7c9e1f34b5d7f9b1d3f5b7d9f1b",
+	}
+	assert.Equal(t, expectedUsers, description.Users)
+}
+
+func TestListLogicalDatabases(t *testing.T) {
+	databases, err := baseProvider.listLogicalDatabases(ctx)
+	assert.Empty(t, err)
+	expectedDatabases := []LogicalDatabase{
+		{Name: "stubprefix", Indices: []string{"stubprefix_customers", "stubprefix_orders"}},
+		{Name: "testme", Indices: []string{"testme"}},
+	}
+	assert.Equal(t, expectedDatabases, databases)
+}
+
+func TestListLogicalDatabasesWithCollidingPrefix(t *testing.T) {
+	databases, err := baseProvider.listLogicalDatabases(ctx)
+	assert.Empty(t, err)
+	for _, database := range databases {
+		assert.NotContains(t, database.Indices, "stubprefix2_orders")
+	}
+}
+
+func TestDescribeDatabaseWithCollidingPrefix(t *testing.T) {
+	description, err := baseProvider.describeDatabase("stubprefix", ctx)
+	assert.Empty(t, err)
+	for _, index := range description.Indices {
+		assert.NotEqual(t, "stubprefix2_orders", index.Name)
+	}
+	assert.Len(t, description.Indices, 2)
+}
+
+func TestDescribeDatabaseWithoutResourcePrefix(t *testing.T) {
+	provider, client := newTestProvider()
+	client.responses["/_cat/indices/testme*"] =
+		`[{"index":"testme","pri":"1","docs.count":"1","store.size":"1024"},{"index":"testme2","pri":"1","docs.count":"5","store.size":"2048"}]`
+	description, err := provider.describeDatabase("testme", ctx)
+	assert.Empty(t, err)
+	assert.Equal(t, []IndexDescription{{Name: "testme", DocsCount: 1, StoreSize: 1024}}, description.Indices)
+}
+
+func TestDescribeDatabaseWithLongerPrefix(t *testing.T) {
+	provider, client := newTestProvider()
+	client.responses["/dbaas_opensearch_metadata/_search"] =
+		`{"hits":{"hits":[{"_id":"app","_source":{"microserviceName":"app"}},{"_id":"app_x","_source":{"microserviceName":"app-x"}}]}}`
+	client.responses[http.MethodGet+" /dbaas_opensearch_metadata/_doc/app"] = `{"found":true,"_source":{"microserviceName":"app"}}`
+	client.responses["/_cat/indices/app*"] =
+		`[{"index":"app_orders","pri":"1","docs.count":"10","store.size":"1024"},{"index":"app_x_orders","pri":"1","docs.count":"5","store.size":"2048"}]`
+	client.responses["/_alias/app*"] = `{"app_orders":{"aliases":{"app_alias":{}}},"app_x_orders":{"aliases":{"app_x_alias":{}}}}`
+	client.responses["/_template/app*"] = `{"app_template":{},"app_x_template":{}}`
+	client.responses["/_index_template/app*"] = `{"index_templates":[{"name":"app_index_template"},{"name":"app_x_index_template"}]}`
+	description, err := provider.describeDatabase("app", ctx)
+	assert.Empty(t, err)
+	assert.Equal(t, []IndexDescription{{Name: "app_orders", DocsCount: 10, StoreSize: 1024}}, description.Indices)
+	assert.Equal(t, []string{"app_alias"}, description.Aliases)
+	assert.Equal(t, []string{"app_template"}, description.Templates)
+	assert.Equal(t, []string{"app_index_template"}, description.IndexTemplates)
+}
+
+func TestCreateDatabaseRollbackOnUserFailure(t *testing.T) {
+	prefix := fmt.Sprintf("%s_rollback", common.FailingUsername)
+	requestOnCreateDb := DbCreateRequest{
+		NamePrefix: prefix,
+		DbName:     "index",
+		Settings: Settings{
+			ResourcePrefix: true,
+			CreateOnly:     []string{"index", "user"},
+		},
+	}
+	response, err := baseProvider.createDatabase(requestOnCreateDb, ctx)
+	assert.Nil(t, response)
+	var rollbackErr *RollbackError
+	assert.ErrorAs(t, err, &rollbackErr)
+	// the user is not created, so only the index is rolled back
+	expectedResources := []dao.DbResource{
+		{Kind: common.IndexKind, Name: fmt.Sprintf("%s_index", prefix), Status: DeletedStatus},
+	}
+	assert.Equal(t, expectedResources, rollbackErr.Resources)
+	assert.True(t, rollbackErr.Response().RollbackSucceeded)
+}
+
+func TestCreateDatabaseWithExistingClassifier(t *testing.T) {
+	requestOnCreateDb := DbCreateRequest{
+		Metadata: map[string]interface{}{
+			"classifier": map[string]interface{}{
+				"namespace":        "stub-namespace",
+				"microserviceName": "stub-service",
+			},
+			"microserviceName": "stub-service",
+		},
+		Settings: Settings{
+			ResourcePrefix: true,
+			CreateOnly:     []string{"user"},
+		},
+	}
+	r, err := baseProvider.createDatabase(requestOnCreateDb, ctx)
+	assert.Empty(t, err)
+	response := r.(DbCreateResponse)
+	expectedUsername := "stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1"
+	assert.Equal(t, "stubprefix", response.ConnectionProperties.ResourcePrefix)
+	assert.Equal(t, expectedUsername, response.ConnectionProperties.Username)
+	assert.Empty(t, response.ConnectionProperties.Password)
+	expectedResources := []dao.DbResource{
+		{Kind: common.ResourcePrefixKind, Name: "stubprefix"},
+		{Kind: common.UserKind, Name: expectedUsername},
+		{Kind: common.MetadataKind, Name: "stubprefix"},
+	}
+	assert.Equal(t, expectedResources, response.Resources)
+
+	requestOnCreateDb.Settings.RotatePasswords = true
+	r, err = baseProvider.createDatabase(requestOnCreateDb, ctx)
+	assert.Empty(t, err)
+	response = r.(DbCreateResponse)
+	assert.Equal(t, expectedUsername, response.ConnectionProperties.Username)
+	assert.NotEmpty(t, response.ConnectionProperties.Password)
+}
+
+func TestClassifierQuery(t *testing.T) {
+	classifier := map[string]interface{}{
+		"namespace":        "stub-namespace",
+		"microserviceName": "stub-service",
+		"isService":        true,
+		"custom_keys":      map[string]interface{}{"logicalDbName": "orders"},
+	}
+	query, err := json.Marshal(classifierQuery(classifier))
+	assert.NoError(t, err)
+	expectedQuery := `{"query":{"bool":{"filter":[` +
+		`{"term":{"classifier.custom_keys.logicalDbName.keyword":"orders"}},` +
+		`{"term":{"classifier.isService":true}},` +
+		`{"term":{"classifier.microserviceName.keyword":"stub-service"}},` +
+		`{"term":{"classifier.namespace.keyword":"stub-namespace"}}]}}}`
+	assert.JSONEq(t, expectedQuery, string(query))
+}
+
+func TestClassifierLockSerialisesCreation(t *testing.T) {
+	locks := newKeyedMutex()
+	unlock := locks.lock("classifier")
+	locked := make(chan struct{})
+	go func() {
+		defer close(locked)
+		locks.lock("classifier")()
+	}()
+	// other classifiers are not blocked
+	locks.lock("other")()
+	select {
+	case <-locked:
+		t.Fatal("the same classifier is locked twice")
+	case <-time.After(10 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+	assert.Empty(t, locks.locks)
+}
+
+func TestCreateDatabaseHandlerWithInvalidBody(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/databases", strings.NewReader("{invalid"))
+	recorder := httptest.NewRecorder()
+	baseProvider.CreateDatabaseHandler()(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	var response common.Error
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, common.InvalidRequestBodyCode, response.Code)
+	assert.NotEmpty(t, response.RequestId)
+}
+
+func TestCreateDatabaseHandlerWithForbiddenPrefix(t *testing.T) {
+	body := `{"namePrefix":".forbidden","settings":{"resourcePrefix":true,"createOnly":["user"]}}`
+	request := httptest.NewRequest(http.MethodPost, "/databases", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	baseProvider.CreateDatabaseHandler()(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	var response common.Error
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, common.ValidationErrorCode, response.Code)
+	assert.Equal(t, "request validation failed: namePrefix: must not start with any of '-_+.'", response.Message)
+}
+
+func TestValidateDbCreateRequest(t *testing.T) {
+	body := `{
+		"dbName": "Orders",
+		"namePrefix": "` + strings.Repeat("p", maxPrefixLength+1) + `",
+		"metadata": {"classifier": {"namespace": 1}, "microserviceName": true},
+		"settings": {"resourcePrefix": true, "createOnly": ["user", "template"], "indexSettings": "shards", "unknown": 1},
+		"extra": "value"
+	}`
+	var request DbCreateRequest
+	violations, err := decodeRequest(strings.NewReader(body), &request)
+	assert.NoError(t, err)
+	violations = append(violations, baseProvider.validateDbCreateRequest(request)...)
+	expectedViolations := []Violation{
+		{Field: "extra", Message: "unknown field"},
+		{Field: "settings.unknown", Message: "unknown field"},
+		{Field: "metadata.classifier.namespace", Message: "must be a string"},
+		{Field: "metadata.microserviceName", Message: "must be a string"},
+		{Field: "settings.createOnly[1]", Message: "unsupported resource kind 'template', allowed values are 'user' and 'index'"},
+		{Field: "settings.indexSettings", Message: "must be an object"},
+		{Field: "namePrefix", Message: "must not be longer than 64 bytes"},
+		{Field: "dbName", Message: "must be lowercase"},
+	}
+	assert.Equal(t, expectedViolations, violations)
+}
+
+func TestValidateUserCreateRequest(t *testing.T) {
+	request := dao.UserCreateRequest{DbName: "Bad*Prefix", Role: "superuser"}
+	violations := baseProvider.validateUserCreateRequest(request)
+	expectedViolations := []Violation{
+		{Field: "role", Message: "unsupported role 'superuser', allowed values are [readonly dml admin ism]"},
+		{Field: "dbName", Message: "must be lowercase"},
+		{Field: "dbName", Message: fmt.Sprintf("must not contain any of '%s'", indexNameForbiddenCharacters)},
+	}
+	assert.Equal(t, expectedViolations, violations)
+
+	// index names are not limited by rules of resource prefixes
+	request = dao.UserCreateRequest{DbName: "_" + strings.Repeat("a", 100)}
+	assert.Empty(t, baseProvider.validateUserCreateRequest(request))
+	request = dao.UserCreateRequest{DbName: strings.Repeat("a", 256)}
+	violations = baseProvider.validateUserCreateRequest(request)
+	assert.Equal(t, []Violation{{Field: "dbName", Message: "must not be longer than 255 bytes"}}, violations)
+
+	// users of the resource prefix are requested with trailing `*`
+	request = dao.UserCreateRequest{DbName: "stubprefix*"}
+	assert.Empty(t, baseProvider.validateUserCreateRequest(request))
+}
+
+func TestConnectionPropertiesWithAdvertisedNodes(t *testing.T) {
+	provider := BaseProvider{
+		opensearch: &cluster.Opensearch{
+			Host:     "opensearch.opensearch-service",
+			Port:     9200,
+			Protocol: common.Http,
+			Nodes:    []string{"http://opensearch-0:9200", "http://opensearch-1:9200"},
+		},
+	}
+	connectionProperties := provider.getConnectionProperties("index", "user", "password")
+	assert.Equal(t, "http://opensearch.opensearch-service:9200/index", connectionProperties.Url)
+	assert.Empty(t, connectionProperties.Nodes)
+
+	provider.opensearch.AdvertiseNodes = true
+	connectionProperties = provider.getConnectionProperties("index", "user", "password")
+	assert.Equal(t, "http://opensearch.opensearch-service:9200/index", connectionProperties.Url)
+	assert.Equal(t, []string{"http://opensearch-0:9200", "http://opensearch-1:9200"}, connectionProperties.Nodes)
+}
@@ -0,0 +1,218 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/api"
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+)
+
+// databaseRoleNamePattern is the name of the role with additional permissions of the database for one role type,
+// such roles have their own namespace, so they never match names of roles shared by all databases.
+const databaseRoleNamePattern = "dbaas_db_%s" + resourcePrefixDelimiter + "%s"
+
+// allowedAdditionalClusterPermissions are cluster actions which can be requested as additional permissions, values
+// ending with '/' allow all actions under them. They are read-only, so databases cannot affect each other.
+var allowedAdditionalClusterPermissions = []string{
+	"cluster:monitor/",
+	"cluster:admin/repository/get",
+	"cluster:admin/snapshot/get",
+	"cluster:admin/snapshot/status",
+}
+
+// AdditionalPermissions are granted to users of one role type of the database in addition to permissions of the role type.
+type AdditionalPermissions struct {
+	ClusterPermissions []string `json:"clusterPermissions,omitempty"`
+	// IndexPermissions are allowed on indices which start with the resource prefix of the database followed by delimiter
+	IndexPermissions []string `json:"indexPermissions,omitempty"`
+}
+
+// databaseRoleDescriptionPattern marks roles created for the database, only marked roles are deleted with the resource prefix.
+const databaseRoleDescriptionPattern = "Additional permissions of '%s' database"
+
+func databaseRoleDescription(prefix string) string {
+	return fmt.Sprintf(databaseRoleDescriptionPattern, prefix)
+}
+
+// databaseRolePrefix returns the resource prefix of the database the role is created for. The role belongs
+// to the database only if both its description and its name are built from the prefix.
+func databaseRolePrefix(name string, role Role) (string, bool) {
+	before, after, _ := strings.Cut(databaseRoleDescriptionPattern, "%s")
+	prefix, ok := strings.CutPrefix(role.Description, before)
+	if !ok {
+		return "", false
+	}
+	prefix, ok = strings.CutSuffix(prefix, after)
+	if !ok || !strings.HasPrefix(name, fmt.Sprintf(databaseRoleNamePattern, prefix, "")) {
+		return "", false
+	}
+	return prefix, true
+}
+
+func isAllowedAdditionalClusterPermission(permission string) bool {
+	for _, allowed := range allowedAdditionalClusterPermissions {
+		if permission == allowed || strings.HasSuffix(allowed, "/") && strings.HasPrefix(permission, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// createDatabaseRoles creates a role with additional permissions for each role type and maps it to the users
+// of the role type. Roles created before the failure are returned together with the error to be rolled back.
+func (bp BaseProvider) createDatabaseRoles(prefix string, permissions map[string]AdditionalPermissions,
+	usersByRoleType map[string][]string, ctx context.Context) ([]dao.DbResource, error) {
+	roleTypes := make([]string, 0, len(permissions))
+	for roleType := range permissions {
+		roleTypes = append(roleTypes, roleType)
+	}
+	sort.Strings(roleTypes)
+	var created []dao.DbResource
+	for _, roleType := range roleTypes {
+		name := fmt.Sprintf(databaseRoleNamePattern, prefix, roleType)
+		existing, err := bp.GetRole(name)
+		if err != nil {
+			return created, err
+		}
+		if existing != nil && existing.Description != databaseRoleDescription(prefix) {
+			return created, common.NewConflictError(common.PrefixConflictCode,
+				fmt.Errorf("'%s' role already exists and does not belong to '%s' database", name, prefix))
+		}
+		logger.InfoContext(ctx, fmt.Sprintf("Creating '%s' role with additional permissions of '%s' users", name, roleType))
+		role := Role{
+			Description:        databaseRoleDescription(prefix),
+			ClusterPermissions: permissions[roleType].ClusterPermissions,
+			IndexPermissions:   []IndexPermission{},
+		}
+		if len(permissions[roleType].IndexPermissions) > 0 {
+			role.IndexPermissions = append(role.IndexPermissions, IndexPermission{
+				IndexPatterns:  []string{prefix + resourcePrefixDelimiter + "*"},
+				AllowedActions: permissions[roleType].IndexPermissions,
+			})
+		}
+		if err = bp.putRole(name, role); err != nil {
+			return created, err
+		}
+		created = append(created, dao.DbResource{Kind: common.RoleKind, Name: name})
+		if err = bp.createRoleMapping(name, RoleMapping{Users: usersByRoleType[roleType]}); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// getDatabaseRoles returns sorted names of roles created for the database with the given resource prefix.
+func (bp BaseProvider) getDatabaseRoles(prefix string) ([]string, error) {
+	getRolesRequest := api.GetRolesRequest{}
+	response, err := getRolesRequest.Do(context.Background(), bp.opensearch.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive roles: %+v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("during receiving roles error occurred: %+v", response.Body)
+	}
+	var roles map[string]Role
+	if err = common.ProcessBody(response.Body, &roles); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for name, role := range roles {
+		if rolePrefix, ok := databaseRolePrefix(name, role); ok && rolePrefix == prefix {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// syncDatabaseRoleUsers maps roles with additional permissions of the database to its current users of their
+// role types, so users created after the database and shadow users of credentials rotation get them as well.
+// Role mappings removed by soft delete and users disabled by it are left as is.
+func (bp BaseProvider) syncDatabaseRoleUsers(prefix string, ctx context.Context) error {
+	roles, err := bp.getDatabaseRoles(prefix)
+	if err != nil || len(roles) == 0 {
+		return err
+	}
+	users, err := bp.getUsers()
+	if err != nil {
+		return err
+	}
+	usersByRoleType := make(map[string][]string)
+	for name, user := range users {
+		if user.Attributes[resourcePrefixAttributeName] == prefix && len(user.Roles) > 0 {
+			roleType := bp.roleTypeByBackendRoles(user.Roles)
+			usersByRoleType[roleType] = append(usersByRoleType[roleType], name)
+		}
+	}
+	for _, name := range roles {
+		mapping, err := bp.GetRoleMapping(name)
+		if err != nil {
+			return err
+		}
+		if mapping == nil {
+			continue
+		}
+		roleType := strings.TrimPrefix(name, fmt.Sprintf(databaseRoleNamePattern, prefix, ""))
+		roleUsers := usersByRoleType[roleType]
+		sort.Strings(roleUsers)
+		if sameElements(mapping.Users, roleUsers) {
+			continue
+		}
+		logger.InfoContext(ctx, fmt.Sprintf("Updating users of '%s' role with additional permissions", name))
+		if err = bp.createRoleMapping(name, RoleMapping{Users: roleUsers}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteDatabaseRole deletes the role mapping and then the role, so users lose permissions first.
+func (bp BaseProvider) deleteDatabaseRole(name string, ctx context.Context) error {
+	if err := bp.deleteRoleMapping(name); err != nil {
+		return err
+	}
+	deleteRoleRequest := api.DeleteRoleRequest{Role: name}
+	response, err := deleteRoleRequest.Do(ctx, bp.opensearch.Client)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete '%s' role: [%d] %+v", name, response.StatusCode, response.Body)
+	}
+	logger.InfoContext(ctx, fmt.Sprintf("Role with name [%s] is removed", name))
+	return nil
+}
+
+func (bp BaseProvider) deleteRoleMapping(name string) error {
+	deleteRolesMappingRequest := api.DeleteRolesMappingRequest{Role: name}
+	response, err := deleteRolesMappingRequest.Do(context.Background(), bp.opensearch.Client)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete role mapping for '%s' role: [%d] %+v", name, response.StatusCode, response.Body)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDatabaseWithAdditionalPermissions(t *testing.T) {
+	provider, client := newTestProvider()
+	client.stubSecurity()
+	request := DbCreateRequest{
+		Settings: Settings{
+			ResourcePrefix: true,
+			CreateOnly:     []string{common.UserKind},
+			AdditionalPermissions: map[string]AdditionalPermissions{
+				AdminRoleType:    {IndexPermissions: []string{"indices:admin/resize"}},
+				ReadOnlyRoleType: {ClusterPermissions: []string{"cluster:admin/snapshot/get"}},
+			},
+		},
+	}
+	r, err := provider.createDatabase(request, ctx)
+	require.NoError(t, err)
+	response := r.(DbCreateResponseMultiUser)
+	prefix := response.ConnectionProperties[0].ResourcePrefix
+	usernames := make(map[string]string)
+	for _, connectionProperties := range response.ConnectionProperties {
+		usernames[connectionProperties.Role] = connectionProperties.Username
+	}
+	adminRole := fmt.Sprintf(databaseRoleNamePattern, prefix, AdminRoleType)
+	readOnlyRole := fmt.Sprintf(databaseRoleNamePattern, prefix, ReadOnlyRoleType)
+	assert.Equal(t, "dbaas_db_"+prefix+"_admin", adminRole)
+	assert.Contains(t, response.Resources, dao.DbResource{Kind: common.RoleKind, Name: adminRole})
+	assert.Contains(t, response.Resources, dao.DbResource{Kind: common.RoleKind, Name: readOnlyRole})
+
+	assert.Equal(t, Role{
+		Description: databaseRoleDescription(prefix),
+		IndexPermissions: []IndexPermission{
+			{IndexPatterns: []string{prefix + "_*"}, AllowedActions: []string{"indices:admin/resize"}},
+		},
+	}, client.roles[adminRole])
+	assert.Equal(t, []string{"cluster:admin/snapshot/get"}, client.roles[readOnlyRole].ClusterPermissions)
+	assert.Empty(t, client.roles[readOnlyRole].IndexPermissions)
+	assert.Equal(t, []string{usernames[AdminRoleType]}, client.mappings[adminRole].Users)
+	assert.Equal(t, []string{usernames[ReadOnlyRoleType]}, client.mappings[readOnlyRole].Users)
+	assert.NotContains(t, client.roles, fmt.Sprintf(databaseRoleNamePattern, prefix, DmlRoleType))
+}
+
+func TestCreateDatabaseWithAdditionalPermissionsKeepsSharedRoles(t *testing.T) {
+	provider, client := newTestProvider()
+	client.stubSecurity()
+	sharedRole := fmt.Sprintf(common.RoleNamePattern, AdminRoleType)
+	client.roles[sharedRole] = adminRoleTypeDefinition().role()
+	client.mappings[sharedRole] = RoleMapping{Users: []string{"tenant"}, BackendRoles: []string{"dbaas_admin"}}
+	request := DbCreateRequest{
+		NamePrefix: "dbaas",
+		Settings: Settings{
+			ResourcePrefix:        true,
+			CreateOnly:            []string{common.UserKind},
+			AdditionalPermissions: map[string]AdditionalPermissions{AdminRoleType: {IndexPermissions: []string{"indices:admin/resize"}}},
+		},
+	}
+	_, err := provider.createDatabase(request, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, adminRoleTypeDefinition().role(), client.roles[sharedRole])
+	assert.Equal(t, RoleMapping{Users: []string{"tenant"}, BackendRoles: []string{"dbaas_admin"}}, client.mappings[sharedRole])
+	assert.Equal(t, databaseRoleDescription("dbaas"), client.roles["dbaas_db_dbaas_admin"].Description)
+
+	client.roles["dbaas_db_app_admin"] = Role{ClusterPermissions: []string{"cluster_all"}}
+	request.NamePrefix = "app"
+	_, err = provider.createDatabase(request, ctx)
+	assertErrorStatus(t, http.StatusConflict, err)
+	assert.Equal(t, []string{"cluster_all"}, client.roles["dbaas_db_app_admin"].ClusterPermissions)
+}
+
+func TestSyncDatabaseRoleUsers(t *testing.T) {
+	provider, client := newTestProvider()
+	client.stubSecurity()
+	role := fmt.Sprintf(databaseRoleNamePattern, "stubprefix", AdminRoleType)
+	client.mappings[role] = RoleMapping{Users: []string{"stubprefix_expired"}}
+	require.NoError(t, provider.syncDatabaseRoleUsers("stubprefix", ctx))
+	assert.Equal(t, []string{"stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1"}, client.mappings[role].Users)
+
+	// role mappings removed by soft delete are not restored
+	delete(client.mappings, role)
+	require.NoError(t, provider.syncDatabaseRoleUsers("stubprefix", ctx))
+	assert.NotContains(t, client.mappings, role)
+}
+
+func TestGetDatabaseRoles(t *testing.T) {
+	roles, err := bp.getDatabaseRoles("stubprefix")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dbaas_db_stubprefix_admin"}, roles)
+
+	roles, err = bp.getDatabaseRoles("test")
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+}
+
+func TestDeleteResourcesByPrefixWithDatabaseRoles(t *testing.T) {
+	deletedResources := bp.deleteResources([]dao.DbResource{{Kind: common.ResourcePrefixKind, Name: "stubprefix"}},
+		context.Background())
+	assert.Empty(t, getResourcesWithFailedStatus(deletedResources))
+	assert.Equal(t, dao.DbResource{Kind: common.RoleKind, Name: "dbaas_db_stubprefix_admin", Status: DeletedStatus},
+		deletedResources[0])
+	assert.NotContains(t, deletedResources, dao.DbResource{Kind: common.RoleKind, Name: "dbaas_db_stubprefix2_admin", Status: DeletedStatus})
+
+	sharedRole := fmt.Sprintf(common.RoleNamePattern, AdminRoleType)
+	deletedResources = bp.deleteResources([]dao.DbResource{{Kind: common.RoleKind, Name: sharedRole}}, context.Background())
+	require.Len(t, deletedResources, 1)
+	assert.Equal(t, dao.DropResourceStatus(DeletionFailedStatus), deletedResources[0].Status)
+	resolvedResources, err := bp.resolveResources([]dao.DbResource{{Kind: common.RoleKind, Name: sharedRole}}, ctx)
+	require.NoError(t, err)
+	assert.Empty(t, resolvedResources)
+}
+
+func TestValidateAdditionalPermissions(t *testing.T) {
+	settings := Settings{
+		CreateOnly: []string{common.IndexKind},
+		AdditionalPermissions: map[string]AdditionalPermissions{
+			AdminRoleType: {ClusterPermissions: []string{" "}},
+			"superuser":   {IndexPermissions: []string{"indices:admin/resize"}},
+			DmlRoleType:   {},
+		},
+	}
+	violations := bp.validateAdditionalPermissions(settings)
+	expectedViolations := []Violation{
+		{Field: "settings.additionalPermissions", Message: "requires 'resourcePrefix' setting"},
+		{Field: "settings.additionalPermissions", Message: "requires 'user' resource kind in 'createOnly' setting"},
+		{Field: "settings.additionalPermissions.admin.clusterPermissions[0]", Message: "must not be empty"},
+		{Field: "settings.additionalPermissions.dml", Message: "cluster or index permissions must be specified"},
+		{Field: "settings.additionalPermissions.superuser", Message: "unsupported role 'superuser', allowed values are [readonly dml admin ism]"},
+	}
+	assert.Equal(t, expectedViolations, violations)
+
+	settings = Settings{
+		ResourcePrefix:        true,
+		AdditionalPermissions: map[string]AdditionalPermissions{DmlRoleType: {IndexPermissions: []string{"indices:admin/resize"}}},
+	}
+	assert.Empty(t, bp.validateAdditionalPermissions(settings))
+
+	settings.AdditionalPermissions = map[string]AdditionalPermissions{AdminRoleType: {ClusterPermissions: []string{
+		"cluster:monitor/health", "cluster:admin/snapshot/get", "cluster:admin/*", "*", "cluster_all",
+	}}}
+	violations = bp.validateAdditionalPermissions(settings)
+	require.Len(t, violations, 3)
+	for i, permission := range []string{"cluster:admin/*", "*", "cluster_all"} {
+		assert.Equal(t, fmt.Sprintf("settings.additionalPermissions.admin.clusterPermissions[%d]", i+2), violations[i].Field)
+		assert.Contains(t, violations[i].Message, fmt.Sprintf("unsupported cluster permission '%s'", permission))
+	}
+
+	settings.AdditionalPermissions = map[string]AdditionalPermissions{DmlRoleType: {IndexPermissions: []string{"indices:admin/resize"}}}
+	v1Provider := bp
+	v1Provider.ApiVersion = common.ApiV1
+	assert.Equal(t, []Violation{
+		{Field: "settings.additionalPermissions.dml", Message: "unsupported role 'dml', allowed values are [admin]"},
+	}, v1Provider.validateAdditionalPermissions(settings))
+}
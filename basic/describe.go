@@ -0,0 +1,309 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+type DatabaseDescription struct {
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Indices        []IndexDescription     `json:"indices"`
+	Aliases        []string               `json:"aliases"`
+	Templates      []string               `json:"templates"`
+	IndexTemplates []string               `json:"indexTemplates"`
+	Users          []string               `json:"users"`
+	Quota          *QuotaStatus           `json:"quota,omitempty"`
+}
+
+type IndexDescription struct {
+	Name      string `json:"name"`
+	DocsCount int64  `json:"docsCount"`
+	StoreSize int64  `json:"storeSize"`
+}
+
+// catIndex is an entry of `_cat/indices` response in JSON format, all values are returned as strings.
+type catIndex struct {
+	Index        string `json:"index"`
+	Status       string `json:"status"`
+	Primaries    string `json:"pri"`
+	DocsCount    string `json:"docs.count"`
+	StoreSize    string `json:"store.size"`
+	PriStoreSize string `json:"pri.store.size"`
+}
+
+type indexAliases struct {
+	Aliases map[string]interface{} `json:"aliases"`
+}
+
+func (bp BaseProvider) DescribeDatabasesHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.PrepareContext(r)
+		logger.InfoContext(ctx, "Request to describe databases is received")
+		decoder := json.NewDecoder(r.Body)
+		var databases []string
+		err := decoder.Decode(&databases)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to decode request in describe databases handler", slog.Any("error", err))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
+			return
+		}
+		defer r.Body.Close()
+		descriptions, err := bp.describeDatabases(databases, ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to describe databases", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		responseBody, err := json.Marshal(descriptions)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to serialize databases description", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}
+}
+
+// describeDatabases collects information about each database, where database is either resource prefix or index name.
+func (bp BaseProvider) describeDatabases(databases []string, ctx context.Context) (map[string]DatabaseDescription, error) {
+	result := make(map[string]DatabaseDescription, len(databases))
+	for _, database := range databases {
+		if database == "" {
+			continue
+		}
+		description, err := bp.describeDatabase(database, ctx)
+		if err != nil {
+			return nil, err
+		}
+		result[database] = description
+	}
+	return result, nil
+}
+
+func (bp BaseProvider) describeDatabase(name string, ctx context.Context) (DatabaseDescription, error) {
+	logger.InfoContext(ctx, fmt.Sprintf("Describing '%s' database", name))
+	var description DatabaseDescription
+	var err error
+	// the pattern matches the index of database created without resource prefix and resources of longer prefixes,
+	// so found resources are filtered by their owner
+	namePattern := name + "*"
+	description.Metadata, err = bp.GetMetadata(name, ctx)
+	if err != nil {
+		return description, err
+	}
+	prefixes, _, err := bp.getDatabasePrefixes(ctx)
+	if err != nil {
+		return description, err
+	}
+	found, err := bp.getIndicesByPattern(namePattern, ctx)
+	if err != nil {
+		return description, err
+	}
+	indices := make([]catIndex, 0, len(found))
+	description.Indices = make([]IndexDescription, 0, len(found))
+	for _, index := range found {
+		if !isOwnedBy(index.Index, name, prefixes) {
+			continue
+		}
+		indices = append(indices, index)
+		description.Indices = append(description.Indices, IndexDescription{
+			Name:      index.Index,
+			DocsCount: parseCatValue(index.DocsCount),
+			StoreSize: parseCatValue(index.StoreSize),
+		})
+	}
+	aliases, err := bp.getAliasesByPattern(namePattern, ctx)
+	if err != nil {
+		return description, err
+	}
+	description.Aliases = ownedResources(aliases, name, prefixes)
+	templates, err := bp.getTemplatesByPattern(namePattern, ctx)
+	if err != nil {
+		return description, err
+	}
+	description.Templates = ownedResources(templates, name, prefixes)
+	indexTemplates, err := bp.getIndexTemplatesByPattern(namePattern, ctx)
+	if err != nil {
+		return description, err
+	}
+	description.IndexTemplates = ownedResources(indexTemplates, name, prefixes)
+	if description.Users, err = bp.getUsersByResourcePrefix(name); err != nil {
+		return description, err
+	}
+	if quota := quotaOf(description.Metadata); quota != nil {
+		usage := quotaUsage(indices, name, prefixes)
+		description.Quota = &QuotaStatus{Quota: *quota, Usage: usage, Exceeded: quota.isReachedBy(usage)}
+	}
+	return description, nil
+}
+
+// isOwnedBy checks whether the resource is the index of database created without resource prefix
+// or belongs to the database with the given prefix rather than to a database with longer prefix.
+func isOwnedBy(resource string, name string, prefixes map[string]struct{}) bool {
+	return resource == name || databaseOwner(resource, name, prefixes) == name
+}
+
+// ownedResources returns resources which belong to the database with the given name.
+func ownedResources(resources []string, name string, prefixes map[string]struct{}) []string {
+	owned := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		if isOwnedBy(resource, name, prefixes) {
+			owned = append(owned, resource)
+		}
+	}
+	return owned
+}
+
+func (bp BaseProvider) getIndicesByPattern(pattern string, ctx context.Context) ([]catIndex, error) {
+	indicesRequest := opensearchapi.CatIndicesRequest{
+		Format: "json",
+		Bytes:  "b",
+		H:      []string{"index", "status", "pri", "docs.count", "store.size", "pri.store.size"},
+	}
+	if pattern != "" {
+		indicesRequest.Index = []string{pattern}
+	}
+	indices := make([]catIndex, 0)
+	err := common.DoRequest(indicesRequest, bp.opensearch.Client, &indices, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred during retrieving indices by '%s' pattern: %+v", pattern, err)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i].Index < indices[j].Index
+	})
+	return indices, nil
+}
+
+func (bp BaseProvider) getAliasesByPattern(pattern string, ctx context.Context) ([]string, error) {
+	getAliasRequest := opensearchapi.IndicesGetAliasRequest{
+		Name: []string{pattern},
+	}
+	response, err := getAliasRequest.Do(ctx, bp.opensearch.Client)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	aliases := make([]string, 0)
+	if response.StatusCode == http.StatusNotFound {
+		return aliases, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("during receiving aliases by '%s' pattern error occurred: %+v", pattern, response.Body)
+	}
+	var indices map[string]indexAliases
+	if err = common.ProcessBody(response.Body, &indices); err != nil {
+		return nil, err
+	}
+	found := make(map[string]struct{})
+	for _, index := range indices {
+		for alias := range index.Aliases {
+			if _, ok := found[alias]; !ok {
+				found[alias] = struct{}{}
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+func (bp BaseProvider) getTemplatesByPattern(pattern string, ctx context.Context) ([]string, error) {
+	getTemplateRequest := opensearchapi.IndicesGetTemplateRequest{
+		Name: []string{pattern},
+	}
+	response, err := getTemplateRequest.Do(ctx, bp.opensearch.Client)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	templates := make([]string, 0)
+	if response.StatusCode == http.StatusNotFound {
+		return templates, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("during receiving templates by '%s' pattern error occurred: %+v", pattern, response.Body)
+	}
+	var found map[string]interface{}
+	if err = common.ProcessBody(response.Body, &found); err != nil {
+		return nil, err
+	}
+	for template := range found {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	return templates, nil
+}
+
+func (bp BaseProvider) getIndexTemplatesByPattern(pattern string, ctx context.Context) ([]string, error) {
+	getIndexTemplateRequest := opensearchapi.IndicesGetIndexTemplateRequest{
+		Name: []string{pattern},
+	}
+	response, err := getIndexTemplateRequest.Do(ctx, bp.opensearch.Client)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	templates := make([]string, 0)
+	if response.StatusCode == http.StatusNotFound {
+		return templates, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("during receiving index templates by '%s' pattern error occurred: %+v", pattern, response.Body)
+	}
+	var found map[string][]IndexTemplate
+	if err = common.ProcessBody(response.Body, &found); err != nil {
+		return nil, err
+	}
+	for _, template := range found["index_templates"] {
+		templates = append(templates, template.Name)
+	}
+	sort.Strings(templates)
+	return templates, nil
+}
+
+// getUsersByResourcePrefix returns names of users which have `resource_prefix` attribute equal to the given prefix
+func (bp BaseProvider) getUsersByResourcePrefix(prefix string) ([]string, error) {
+	users, err := bp.getUsers()
+	if err != nil {
+		return nil, err
+	}
+	usersByPrefix := make([]string, 0)
+	for name, user := range users {
+		if user.Attributes[resourcePrefixAttributeName] == prefix {
+			usersByPrefix = append(usersByPrefix, name)
+		}
+	}
+	sort.Strings(usersByPrefix)
+	return usersByPrefix, nil
+}
+
+func parseCatValue(value string) int64 {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
@@ -0,0 +1,312 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+const classifierMetadataKey = "classifier"
+
+// getExistingDatabase returns connection properties of the database previously created for the same classifier.
+// It returns nil if there is no such database or some of its resources are missing, so the database should be created.
+func (bp BaseProvider) getExistingDatabase(requestOnCreateDb DbCreateRequest, ctx context.Context) (interface{}, error) {
+	classifier, ok := requestOnCreateDb.Metadata[classifierMetadataKey]
+	if !ok || classifier == nil {
+		return nil, nil
+	}
+	metadataID, err := bp.findMetadataByClassifier(classifier, ctx)
+	if err != nil || metadataID == "" {
+		return nil, err
+	}
+	logger.InfoContext(ctx, fmt.Sprintf("Database with the same classifier is found by '%s' metadata", metadataID))
+
+	users, err := bp.getUsers()
+	if err != nil {
+		return nil, err
+	}
+	prefix := findResourcePrefix(metadataID, users)
+	if prefix == "" {
+		logger.WarnContext(ctx, fmt.Sprintf("There are no users for '%s' database, it is created again", metadataID))
+		return nil, nil
+	}
+	var indexName string
+	if !requestOnCreateDb.Settings.ResourcePrefix || metadataID != prefix {
+		indexName = metadataID
+		index, err := bp.getDatabase(indexName)
+		if err != nil {
+			return nil, err
+		}
+		if index == nil {
+			logger.WarnContext(ctx, fmt.Sprintf("'%s' index does not exist, database is created again", indexName))
+			return nil, nil
+		}
+	}
+
+	roleTypes := []string{AdminRoleType}
+	if bp.ApiVersion == common.ApiV2 {
+		roleTypes = bp.GetSupportedRoleTypes()
+	}
+	usernames := make(map[string]string, len(roleTypes))
+	for _, roleType := range roleTypes {
+		username := bp.findUserByBackendRoles(prefix, users, bp.GetBackendRoles(roleType))
+		if username == "" {
+			logger.WarnContext(ctx, fmt.Sprintf("User with '%s' role for '%s' prefix does not exist, database is created again",
+				roleType, prefix))
+			return nil, nil
+		}
+		usernames[roleType] = username
+	}
+
+	var resources []dao.DbResource
+	if requestOnCreateDb.Settings.ResourcePrefix {
+		resources = append(resources, dao.DbResource{Kind: common.ResourcePrefixKind, Name: prefix})
+	}
+	if indexName != "" {
+		resources = append(resources, dao.DbResource{Kind: common.IndexKind, Name: indexName})
+	}
+	var connections []common.ConnectionProperties
+	for _, roleType := range roleTypes {
+		username := usernames[roleType]
+		password, err := bp.rotatePasswordIfRequested(username, requestOnCreateDb, ctx)
+		if err != nil {
+			return nil, err
+		}
+		connections = append(connections, bp.GetExtendedConnectionProperties(indexName, username, password, prefix, roleType))
+		resources = append(resources, dao.DbResource{Kind: common.UserKind, Name: username})
+	}
+	resources = append(resources, dao.DbResource{Kind: common.MetadataKind, Name: metadataID})
+
+	if bp.ApiVersion == common.ApiV2 {
+		return DbCreateResponseMultiUser{Name: indexName, ConnectionProperties: connections, Resources: resources}, nil
+	}
+	connection := bp.getConnectionProperties(indexName, connections[0].Username, connections[0].Password)
+	if requestOnCreateDb.Settings.ResourcePrefix {
+		connection.ResourcePrefix = prefix
+	}
+	return DbCreateResponse{Name: indexName, ConnectionProperties: connection, Resources: resources}, nil
+}
+
+// findMetadataByClassifier returns identifier of metadata document with the same classifier or empty string if it is not found.
+func (bp BaseProvider) findMetadataByClassifier(classifier interface{}, ctx context.Context) (string, error) {
+	expected, err := json.Marshal(classifier)
+	if err != nil {
+		return "", err
+	}
+	query, err := json.Marshal(classifierQuery(classifier))
+	if err != nil {
+		return "", err
+	}
+	size := metadataSearchSize
+	searchRequest := opensearchapi.SearchRequest{
+		Index: []string{DbaasMetadata},
+		Body:  strings.NewReader(string(query)),
+		Size:  &size,
+	}
+	var response metadataSearchResponse
+	if err = common.DoRequest(searchRequest, bp.opensearch.Client, &response, ctx); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("Error occurred during search by classifier in '%s' index", DbaasMetadata),
+			slog.Any("error", err))
+		return "", err
+	}
+	var found []string
+	for _, hit := range response.Hits.Hits {
+		stored, ok := hit.Source[classifierMetadataKey]
+		if !ok || stored == nil {
+			continue
+		}
+		// Query matches classifiers with additional fields as well, so found classifiers are compared.
+		// Maps are marshalled with sorted keys, so equal classifiers have equal JSON representations
+		actual, err := json.Marshal(stored)
+		if err != nil {
+			return "", err
+		}
+		if string(actual) == string(expected) {
+			found = append(found, hit.ID)
+		}
+	}
+	if len(found) == 0 {
+		return "", nil
+	}
+	sort.Strings(found)
+	if len(found) > 1 {
+		logger.WarnContext(ctx, fmt.Sprintf("There are several databases with the same classifier: %v, '%s' is used",
+			found, found[0]))
+	}
+	return found[0], nil
+}
+
+// classifierQuery builds search query which filters metadata documents by each field of the classifier
+func classifierQuery(classifier interface{}) map[string]interface{} {
+	filters := classifierFilters(classifierMetadataKey, classifier)
+	if filters == nil {
+		filters = []interface{}{}
+	}
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		},
+	}
+}
+
+// classifierFilters returns `term` filters for values of the classifier by their paths, strings are compared
+// with `keyword` sub-fields of dynamic mapping. Lists and null values are not filtered.
+func classifierFilters(path string, value interface{}) []interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var filters []interface{}
+		for _, key := range keys {
+			filters = append(filters, classifierFilters(path+"."+key, typed[key])...)
+		}
+		return filters
+	case string:
+		return []interface{}{map[string]interface{}{"term": map[string]interface{}{path + ".keyword": typed}}}
+	case nil, []interface{}:
+		return nil
+	default:
+		return []interface{}{map[string]interface{}{"term": map[string]interface{}{path: typed}}}
+	}
+}
+
+// lockClassifier serialises search of the database by classifier of the request and its creation, so repeated
+// requests wait for the running one and receive the database created by it. The returned function releases the lock.
+func (bp BaseProvider) lockClassifier(requestOnCreateDb DbCreateRequest) (func(), error) {
+	classifier, ok := requestOnCreateDb.Metadata[classifierMetadataKey]
+	if !ok || classifier == nil {
+		return func() {}, nil
+	}
+	key, err := json.Marshal(classifier)
+	if err != nil {
+		return nil, err
+	}
+	return bp.classifierLocks.lock(string(key)), nil
+}
+
+// keyedMutex serialises operations with the same key, locks of keys are removed when all holders release them
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	holders int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+func (km *keyedMutex) lock(key string) func() {
+	km.mutex.Lock()
+	lock, ok := km.locks[key]
+	if !ok {
+		lock = &keyedLock{}
+		km.locks[key] = lock
+	}
+	lock.holders++
+	km.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		km.mutex.Lock()
+		defer km.mutex.Unlock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(km.locks, key)
+		}
+	}
+}
+
+func (bp BaseProvider) rotatePasswordIfRequested(username string, requestOnCreateDb DbCreateRequest, ctx context.Context) (string, error) {
+	password := ""
+	if bp.ApiVersion == common.ApiV1 {
+		password = requestOnCreateDb.Password
+	}
+	if password == "" && !requestOnCreateDb.Settings.RotatePasswords {
+		return "", nil
+	}
+	if password == "" {
+		var err error
+		password, err = bp.passwordGenerator.Generate()
+		if err != nil {
+			return "", err
+		}
+	} else if err := bp.passwordGenerator.Validate(password); err != nil {
+		return "", common.NewBadRequestError(common.InvalidPasswordCode, err)
+	}
+	logger.InfoContext(ctx, fmt.Sprintf("Rotating password for existing '%s' user", username))
+	if err := bp.PatchUser(username, password, "", "", ctx); err != nil {
+		return "", fmt.Errorf("during password rotation for '%s' user error occurred: %+v", username, err)
+	}
+	return password, nil
+}
+
+// findResourcePrefix returns the longest `resource_prefix` of users which owns the database with given metadata identifier.
+func findResourcePrefix(metadataID string, users map[string]User) string {
+	var prefix string
+	for _, user := range users {
+		userPrefix := user.Attributes[resourcePrefixAttributeName]
+		if userPrefix == "" || len(userPrefix) <= len(prefix) {
+			continue
+		}
+		if metadataID == userPrefix || strings.HasPrefix(metadataID, userPrefix+"_") {
+			prefix = userPrefix
+		}
+	}
+	return prefix
+}
+
+// findUserByBackendRoles returns active user of the prefix with any of given backend roles, backend roles restricted
+// by quota are considered as original ones.
+func (bp BaseProvider) findUserByBackendRoles(prefix string, users map[string]User, backendRoles []string) string {
+	var found []string
+	for name, user := range users {
+		if user.Attributes[resourcePrefixAttributeName] != prefix || user.Attributes[expiresAtAttributeName] != "" {
+			continue
+		}
+		roles := bp.quotaBackendRoles(user.Roles, false)
+		for _, backendRole := range backendRoles {
+			if slices.Contains(roles, backendRole) {
+				found = append(found, name)
+				break
+			}
+		}
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	sort.Strings(found)
+	return found[0]
+}
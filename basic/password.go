@@ -15,21 +15,244 @@
 package basic
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
 	"github.com/sethvargo/go-password/password"
 )
 
+const (
+	passwordValidationRegexSetting       = "plugins.security.restapi.password_validation_regex"
+	legacyPasswordValidationRegexSetting = "opendistro_security.restapi.password_validation_regex"
+	// maxPasswordGenerationAttempts limits regeneration of passwords which do not match the regex of the cluster
+	maxPasswordGenerationAttempts = 100
+)
+
+// PasswordPolicy describes passwords generated for users, Digits and Symbols are the exact numbers of such characters
+// in the password, other characters are letters.
+type PasswordPolicy struct {
+	Length    int
+	Digits    int
+	Symbols   int
+	SymbolSet string
+	// ClusterRegex enables validation of passwords against `password_validation_regex` of OpenSearch security plugin
+	ClusterRegex bool
+}
+
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		Length:    10,
+		Digits:    1,
+		Symbols:   1,
+		SymbolSet: "_#$@",
+	}
+}
+
+func (p PasswordPolicy) Validate() error {
+	if p.Length <= 0 {
+		return fmt.Errorf("password length must be positive, but it is %d", p.Length)
+	}
+	if p.Digits < 0 || p.Symbols < 0 {
+		return errors.New("number of digits and symbols in password cannot be negative")
+	}
+	if p.Digits+p.Symbols > p.Length {
+		return fmt.Errorf("number of digits and symbols in password (%d) exceeds its length %d", p.Digits+p.Symbols, p.Length)
+	}
+	if p.Symbols > 0 && p.SymbolSet == "" {
+		return errors.New("symbol set of password cannot be empty if password contains symbols")
+	}
+	return nil
+}
+
 type PasswordGenerator struct {
 	generator *password.Generator
+	policy    PasswordPolicy
+	regex     *passwordRegex
 }
 
 func NewPasswordGenerator() PasswordGenerator {
-	generator, err := password.NewGenerator(&password.GeneratorInput{Symbols: "_#$@"})
+	generator, err := newPasswordGenerator(DefaultPasswordPolicy(), nil)
 	if err != nil {
 		panic(err)
 	}
-	return PasswordGenerator{generator: generator}
+	return generator
 }
 
+func newPasswordGenerator(policy PasswordPolicy, regex *passwordRegex) (PasswordGenerator, error) {
+	if err := policy.Validate(); err != nil {
+		return PasswordGenerator{}, err
+	}
+	generator, err := password.NewGenerator(&password.GeneratorInput{Symbols: policy.SymbolSet})
+	if err != nil {
+		return PasswordGenerator{}, err
+	}
+	return PasswordGenerator{generator: generator, policy: policy, regex: regex}, nil
+}
+
+// Generate returns random password according to the policy, passwords which do not match the regex of the cluster are regenerated.
 func (operatorGenerator PasswordGenerator) Generate() (string, error) {
-	return operatorGenerator.generator.Generate(10, 1, 1, false, false)
+	policy := operatorGenerator.policy
+	// characters are repeated only if there are not enough distinct ones for the policy
+	allowRepeat := policy.Length-policy.Digits-policy.Symbols > len(password.LowerLetters)+len(password.UpperLetters) ||
+		policy.Digits > len(password.Digits) || policy.Symbols > len(policy.SymbolSet)
+	for i := 0; i < maxPasswordGenerationAttempts; i++ {
+		generated, err := operatorGenerator.generator.Generate(policy.Length, policy.Digits, policy.Symbols, false, allowRepeat)
+		if err != nil {
+			return "", err
+		}
+		if operatorGenerator.regex == nil || operatorGenerator.regex.MatchString(generated) {
+			return generated, nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate password matching '%s' regex of the cluster in %d attempts, check password policy",
+		operatorGenerator.regex, maxPasswordGenerationAttempts)
+}
+
+// Validate checks the password specified in the request against the regex of the cluster.
+func (operatorGenerator PasswordGenerator) Validate(password string) error {
+	if operatorGenerator.regex == nil || operatorGenerator.regex.MatchString(password) {
+		return nil
+	}
+	return fmt.Errorf("password does not match '%s' password validation regex of the cluster", operatorGenerator.regex)
+}
+
+// ConfigurePasswordPolicy replaces the policy of generated passwords. If ClusterRegex is enabled, password validation regex
+// is received from OpenSearch, so the method must be called before handlers are created.
+func (bp *BaseProvider) ConfigurePasswordPolicy(policy PasswordPolicy) error {
+	var regex *passwordRegex
+	if policy.ClusterRegex {
+		source, err := bp.getPasswordValidationRegex()
+		if err != nil {
+			return err
+		}
+		if source != "" {
+			regex, err = compilePasswordRegex(source)
+			if err != nil {
+				return err
+			}
+			logger.Info(fmt.Sprintf("Passwords are validated with '%s' regex of the cluster", source))
+		}
+	}
+	generator, err := newPasswordGenerator(policy, regex)
+	if err != nil {
+		return fmt.Errorf("invalid password policy: %w", err)
+	}
+	bp.passwordGenerator = generator
+	return nil
+}
+
+// getPasswordValidationRegex returns `password_validation_regex` setting of security plugin,
+// empty string is returned if the setting is not configured.
+func (bp *BaseProvider) getPasswordValidationRegex() (string, error) {
+	includeDefaults := true
+	flatSettings := true
+	request := opensearchapi.ClusterGetSettingsRequest{
+		IncludeDefaults: &includeDefaults,
+		FlatSettings:    &flatSettings,
+	}
+	response, err := request.Do(context.Background(), bp.opensearch.Client)
+	if err != nil {
+		return "", fmt.Errorf("failed to receive cluster settings: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to receive cluster settings: %s", response.String())
+	}
+	var settings map[string]map[string]interface{}
+	if err = common.ProcessBody(response.Body, &settings); err != nil {
+		return "", err
+	}
+	// the same precedence as in OpenSearch: transient settings override persistent ones, which override defaults
+	for _, level := range []string{"transient", "persistent", "defaults"} {
+		for _, name := range []string{passwordValidationRegexSetting, legacyPasswordValidationRegexSetting} {
+			if value, ok := settings[level][name].(string); ok && value != "" {
+				return value, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// passwordRegex matches the whole password the same way as Java `Pattern.matches` used by security plugin.
+// Leading lookahead groups like `(?=.*[A-Z])`, which are usual in such regexes, are not supported by Go,
+// so each of them is checked as a separate expression.
+type passwordRegex struct {
+	source      string
+	expressions []*regexp.Regexp
+}
+
+func compilePasswordRegex(source string) (*passwordRegex, error) {
+	regex := &passwordRegex{source: source}
+	rest := source
+	for strings.HasPrefix(rest, "(?=") {
+		end := closingParenthesis(rest)
+		if end < 0 {
+			return nil, fmt.Errorf("password validation regex '%s' has unbalanced parentheses", source)
+		}
+		if err := regex.add(fmt.Sprintf("^(?:%s)", rest[len("(?="):end])); err != nil {
+			return nil, err
+		}
+		rest = rest[end+1:]
+	}
+	if err := regex.add(fmt.Sprintf("^(?:%s)$", rest)); err != nil {
+		return nil, err
+	}
+	return regex, nil
+}
+
+func (r *passwordRegex) add(expression string) error {
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return fmt.Errorf("password validation regex '%s' is not supported: %w", r.source, err)
+	}
+	r.expressions = append(r.expressions, compiled)
+	return nil
+}
+
+func (r *passwordRegex) MatchString(s string) bool {
+	for _, expression := range r.expressions {
+		if !expression.MatchString(s) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *passwordRegex) String() string {
+	return r.source
+}
+
+// closingParenthesis returns the index of parenthesis which closes the first one in the expression,
+// escaped characters and character classes are skipped.
+func closingParenthesis(expression string) int {
+	depth := 0
+	inClass := false
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '(':
+			if !inClass {
+				depth++
+			}
+		case ')':
+			if !inClass {
+				depth--
+				if depth == 0 {
+					return i
+				}
+			}
+		}
+	}
+	return -1
 }
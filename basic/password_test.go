@@ -0,0 +1,140 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDefaultPassword(t *testing.T) {
+	generated, err := NewPasswordGenerator().Generate()
+	require.NoError(t, err)
+	assert.Len(t, generated, 10)
+	assert.Equal(t, 1, countCharacters(generated, "0123456789"))
+	assert.Equal(t, 1, countCharacters(generated, "_#$@"))
+}
+
+func TestGeneratePasswordWithPolicy(t *testing.T) {
+	policy := PasswordPolicy{Length: 24, Digits: 4, Symbols: 3, SymbolSet: "!-"}
+	generator, err := newPasswordGenerator(policy, nil)
+	require.NoError(t, err)
+	generated, err := generator.Generate()
+	require.NoError(t, err)
+	assert.Len(t, generated, 24)
+	assert.Equal(t, 4, countCharacters(generated, "0123456789"))
+	assert.Equal(t, 3, countCharacters(generated, "!-"))
+}
+
+func TestInvalidPasswordPolicy(t *testing.T) {
+	policies := []PasswordPolicy{
+		{Length: 0},
+		{Length: 4, Digits: 3, Symbols: 2, SymbolSet: "#"},
+		{Length: 10, Digits: 1, Symbols: 1},
+		{Length: 10, Digits: -1},
+	}
+	for _, policy := range policies {
+		_, err := newPasswordGenerator(policy, nil)
+		assert.Error(t, err, "policy %+v", policy)
+	}
+}
+
+func TestPasswordRegexWithLookaheads(t *testing.T) {
+	regex, err := compilePasswordRegex(`(?=.*[A-Z])(?=.*[^a-zA-Z\d])(?=.*[0-9])(?=.*[a-z]).{8,}`)
+	require.NoError(t, err)
+	assert.True(t, regex.MatchString("Abcdef1#"))
+	assert.False(t, regex.MatchString("Abc1#"))
+	assert.False(t, regex.MatchString("abcdef1#"))
+	assert.False(t, regex.MatchString("Abcdefg1"))
+
+	regex, err = compilePasswordRegex(`[a-z]+`)
+	require.NoError(t, err)
+	assert.True(t, regex.MatchString("abc"))
+	assert.False(t, regex.MatchString("abc1"))
+
+	_, err = compilePasswordRegex(`(?=.*[A-Z)`)
+	assert.Error(t, err)
+}
+
+func TestGeneratePasswordMatchingClusterRegex(t *testing.T) {
+	provider := newPasswordPolicyProvider()
+	err := provider.ConfigurePasswordPolicy(PasswordPolicy{Length: 12, Digits: 2, Symbols: 2, SymbolSet: "_#$@", ClusterRegex: true})
+	require.NoError(t, err)
+	require.NotNil(t, provider.passwordGenerator.regex)
+	for i := 0; i < 20; i++ {
+		generated, err := provider.passwordGenerator.Generate()
+		require.NoError(t, err)
+		assert.Len(t, generated, 12)
+		assert.NoError(t, provider.passwordGenerator.Validate(generated))
+	}
+}
+
+func TestGeneratePasswordNotMatchingClusterRegex(t *testing.T) {
+	provider := newPasswordPolicyProvider()
+	err := provider.ConfigurePasswordPolicy(PasswordPolicy{Length: 12, Digits: 2, ClusterRegex: true})
+	require.NoError(t, err)
+	_, err = provider.passwordGenerator.Generate()
+	assert.Error(t, err)
+}
+
+func TestValidatePasswordOnUserCreation(t *testing.T) {
+	provider := newPasswordPolicyProvider()
+	require.NoError(t, provider.ConfigurePasswordPolicy(PasswordPolicy{Length: 10, Digits: 1, Symbols: 1, SymbolSet: "_#$@", ClusterRegex: true}))
+
+	violations := provider.validateUserCreateRequest(dao.UserCreateRequest{Password: "simple"})
+	assert.Equal(t, "password", violations[0].Field)
+	assert.Empty(t, provider.validateUserCreateRequest(dao.UserCreateRequest{Password: "Simple#123"}))
+
+	_, _, _, err := provider.createOrUpdateUser("policy-user", "simple", "", AdminRoleType, ctx)
+	var apiErr *common.Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, common.InvalidPasswordCode, apiErr.Code)
+}
+
+func TestInvalidPasswordUsers(t *testing.T) {
+	provider := newPasswordPolicyProvider()
+	require.NoError(t, provider.ConfigurePasswordPolicy(PasswordPolicy{Length: 10, Digits: 1, Symbols: 1, SymbolSet: "_#$@", ClusterRegex: true}))
+	invalid := provider.invalidPasswordUsers([]common.ConnectionProperties{
+		{Username: "valid", Password: "Simple#123"},
+		{Username: "invalid", Password: "simple"},
+	})
+	assert.Equal(t, []string{"invalid"}, invalid)
+}
+
+func newPasswordPolicyProvider() *BaseProvider {
+	return &BaseProvider{
+		opensearch:        baseProvider.opensearch,
+		mutex:             &sync.Mutex{},
+		passwordGenerator: NewPasswordGenerator(),
+		ApiVersion:        common.ApiV1,
+	}
+}
+
+func countCharacters(s string, characters string) int {
+	count := 0
+	for _, character := range s {
+		if strings.ContainsRune(characters, character) {
+			count++
+		}
+	}
+	return count
+}
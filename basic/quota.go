@@ -0,0 +1,343 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// quotaMetadataKey is the field of metadata document which keeps quota of the database
+	quotaMetadataKey = "quota"
+	// quotaExceededSuffix is added to backend roles of users whose database reached its quota
+	quotaExceededSuffix = "_quota_exceeded"
+)
+
+// Quota limits resources of the database, zero value means that there is no limit.
+type Quota struct {
+	MaxIndices       int64 `json:"maxIndices,omitempty"`
+	MaxPrimaryShards int64 `json:"maxPrimaryShards,omitempty"`
+	MaxStoreBytes    int64 `json:"maxStoreBytes,omitempty"`
+}
+
+// QuotaUsage is calculated by indices which belong to the database, store includes replicas.
+type QuotaUsage struct {
+	Indices       int64 `json:"indices"`
+	PrimaryShards int64 `json:"primaryShards"`
+	StoreBytes    int64 `json:"storeBytes"`
+}
+
+type QuotaStatus struct {
+	Quota Quota      `json:"quota"`
+	Usage QuotaUsage `json:"usage"`
+	// Exceeded means that any limit is reached, so users of the database cannot create indices
+	Exceeded bool `json:"exceeded"`
+}
+
+func (q Quota) isEmpty() bool {
+	return q == Quota{}
+}
+
+func (q Quota) isReachedBy(usage QuotaUsage) bool {
+	return q.MaxIndices > 0 && usage.Indices >= q.MaxIndices ||
+		q.MaxPrimaryShards > 0 && usage.PrimaryShards >= q.MaxPrimaryShards ||
+		q.MaxStoreBytes > 0 && usage.StoreBytes >= q.MaxStoreBytes
+}
+
+func (bp BaseProvider) SetQuotaHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.PrepareContext(r)
+		prefix := mux.Vars(r)["prefix"]
+		logger.InfoContext(ctx, fmt.Sprintf("Request to set quota of '%s' database is received", prefix))
+		defer r.Body.Close()
+		var quota Quota
+		violations, err := decodeRequest(r.Body, &quota)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to decode request in set quota handler", slog.Any("error", err))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
+			return
+		}
+		violations = append(violations, validateQuota(quota)...)
+		if len(violations) > 0 {
+			err = newValidationError(violations)
+			logger.ErrorContext(ctx, "Set quota request is not valid", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+
+		status, err := bp.setQuota(prefix, quota, ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to set quota of '%s' database", prefix), slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		responseBody, err := json.Marshal(status)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}
+}
+
+func validateQuota(quota Quota) []Violation {
+	var violations []Violation
+	for field, value := range map[string]int64{
+		"maxIndices":       quota.MaxIndices,
+		"maxPrimaryShards": quota.MaxPrimaryShards,
+		"maxStoreBytes":    quota.MaxStoreBytes,
+	} {
+		if value < 0 {
+			violations = append(violations, Violation{Field: field, Message: "must not be negative"})
+		}
+	}
+	slices.SortFunc(violations, func(a, b Violation) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return violations
+}
+
+// setQuota stores the quota in metadata document of the database and enforces it at once,
+// empty quota removes all limits.
+func (bp BaseProvider) setQuota(prefix string, quota Quota, ctx context.Context) (*QuotaStatus, error) {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	metadata, err := bp.GetMetadata(prefix, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		return nil, common.NewNotFoundError(common.NotFoundCode, fmt.Errorf("metadata for '%s' database is not found", prefix))
+	}
+	if quota.isEmpty() {
+		delete(metadata, quotaMetadataKey)
+	} else {
+		metadata[quotaMetadataKey] = quota
+	}
+	if _, err = bp.CreateMetadata(prefix, metadata, ctx); err != nil {
+		return nil, err
+	}
+	prefixes, users, err := bp.getDatabasePrefixes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := map[string]QuotaStatus{prefix: {Quota: quota}}
+	if err = bp.applyQuotas(statuses, users, prefixes, ctx); err != nil {
+		return nil, err
+	}
+	status := statuses[prefix]
+	return &status, nil
+}
+
+// StartQuotaEnforcer checks quotas of databases on start and then with the given interval until shutdown.
+func (bp *BaseProvider) StartQuotaEnforcer(interval time.Duration) {
+	bp.runPeriodically(interval, func() {
+		bp.EnforceQuotas(context.Background())
+	})
+}
+
+// EnforceQuotas revokes creation of indices from users of databases which reached their quotas
+// and grants it back when usage is below the limits or quota is removed.
+func (bp BaseProvider) EnforceQuotas(ctx context.Context) {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	if err := bp.enforceQuotas(ctx); err != nil {
+		logger.ErrorContext(ctx, "Failed to enforce quotas of databases", slog.Any("error", err))
+	}
+}
+
+// enforceQuotas processes databases with quotas and databases whose users are still restricted after quota removal.
+func (bp BaseProvider) enforceQuotas(ctx context.Context) error {
+	metadata, err := bp.listMetadata(ctx)
+	if err != nil {
+		return err
+	}
+	users, err := bp.getUsers()
+	if err != nil {
+		return err
+	}
+	prefixes := databasePrefixes(metadata, users)
+	statuses := make(map[string]QuotaStatus)
+	for prefix, document := range metadata {
+		if quota := quotaOf(document); quota != nil {
+			statuses[prefix] = QuotaStatus{Quota: *quota}
+		}
+	}
+	for name, user := range users {
+		if slices.Equal(user.Roles, bp.quotaBackendRoles(user.Roles, false)) {
+			continue
+		}
+		if owner := userOwner(name, user, prefixes); owner != "" {
+			statuses[owner] = QuotaStatus{Quota: statuses[owner].Quota}
+		}
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	return bp.applyQuotas(statuses, users, prefixes, ctx)
+}
+
+// applyQuotas calculates usage of the given databases and updates backend roles of their users in one batch.
+func (bp BaseProvider) applyQuotas(statuses map[string]QuotaStatus, users map[string]User, prefixes map[string]struct{},
+	ctx context.Context) error {
+	indices, err := bp.getIndicesByPattern("", ctx)
+	if err != nil {
+		return err
+	}
+	var changes []Change
+	for prefix, status := range statuses {
+		status.Usage = quotaUsage(indices, prefix, prefixes)
+		status.Exceeded = status.Quota.isReachedBy(status.Usage)
+		statuses[prefix] = status
+		for _, name := range usersOfPrefix(users, prefix, prefixes) {
+			roles := bp.quotaBackendRoles(users[name].Roles, status.Exceeded)
+			if slices.Equal(roles, users[name].Roles) {
+				continue
+			}
+			if status.Exceeded {
+				logger.InfoContext(ctx, fmt.Sprintf("'%s' database reached its quota, creation of indices is revoked from '%s' user",
+					prefix, name))
+			} else {
+				logger.InfoContext(ctx, fmt.Sprintf("Creation of indices is granted back to '%s' user of '%s' database", name, prefix))
+			}
+			changes = append(changes, Change{Operation: "add", Path: fmt.Sprintf("/%s/backend_roles", name), Value: roles})
+		}
+	}
+	if err = bp.patchUsers(changes, ctx); err != nil {
+		return fmt.Errorf("failed to update backend roles of users according to quotas: %w", err)
+	}
+	return nil
+}
+
+// exceededQuotas returns prefixes of databases which reached their quotas.
+func (bp BaseProvider) exceededQuotas(ctx context.Context) (map[string]bool, error) {
+	metadata, err := bp.listMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exceeded := make(map[string]bool)
+	quotas := make(map[string]Quota)
+	for prefix, document := range metadata {
+		if quota := quotaOf(document); quota != nil {
+			quotas[prefix] = *quota
+		}
+	}
+	if len(quotas) == 0 {
+		return exceeded, nil
+	}
+	users, err := bp.getUsers()
+	if err != nil {
+		return nil, err
+	}
+	indices, err := bp.getIndicesByPattern("", ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefixes := databasePrefixes(metadata, users)
+	for prefix, quota := range quotas {
+		if quota.isReachedBy(quotaUsage(indices, prefix, prefixes)) {
+			exceeded[prefix] = true
+		}
+	}
+	return exceeded, nil
+}
+
+// databaseBackendRoles returns backend roles of the role type for users of the database, they are restricted
+// if the database reached its quota, so writing the user does not grant creation of indices back.
+func (bp BaseProvider) databaseBackendRoles(prefix string, roleType string, ctx context.Context) ([]string, error) {
+	backendRoles := bp.GetBackendRoles(roleType)
+	metadata, err := bp.GetMetadata(prefix, ctx)
+	if err != nil {
+		return nil, err
+	}
+	quota := quotaOf(metadata)
+	if quota == nil {
+		return backendRoles, nil
+	}
+	prefixes, _, err := bp.getDatabasePrefixes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indices, err := bp.getIndicesByPattern(prefix+resourcePrefixDelimiter+"*", ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bp.quotaBackendRoles(backendRoles, quota.isReachedBy(quotaUsage(indices, prefix, prefixes))), nil
+}
+
+// quotaOf returns quota from metadata document of the database, it is nil if there is no quota.
+func quotaOf(metadata map[string]interface{}) *Quota {
+	value, ok := metadata[quotaMetadataKey]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var quota Quota
+	if err = json.Unmarshal(data, &quota); err != nil || quota.isEmpty() {
+		return nil
+	}
+	return &quota
+}
+
+func quotaUsage(indices []catIndex, prefix string, prefixes map[string]struct{}) QuotaUsage {
+	var usage QuotaUsage
+	for _, index := range indices {
+		if databaseOwner(index.Index, prefix, prefixes) != prefix {
+			continue
+		}
+		usage.Indices++
+		usage.PrimaryShards += parseCatValue(index.Primaries)
+		usage.StoreBytes += parseCatValue(index.StoreSize)
+	}
+	return usage
+}
+
+// quotaBackendRoles replaces backend roles which allow creation of indices with their restricted variants
+// if quota is exceeded and restores them otherwise.
+func (bp BaseProvider) quotaBackendRoles(roles []string, exceeded bool) []string {
+	restrictable := bp.GetBackendRolesForMapping(AdminRoleType)
+	result := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if exceeded && slices.Contains(restrictable, role) {
+			role += quotaExceededSuffix
+		} else if original := strings.TrimSuffix(role, quotaExceededSuffix); !exceeded && slices.Contains(restrictable, original) {
+			role = original
+		}
+		result = append(result, role)
+	}
+	return result
+}
+
+func userOwner(name string, user User, prefixes map[string]struct{}) string {
+	if owner := user.Attributes[resourcePrefixAttributeName]; owner != "" {
+		return owner
+	}
+	return databaseOwner(name, "", prefixes)
+}
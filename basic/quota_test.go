@@ -0,0 +1,153 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubExceededQuota returns metadata of 'stubprefix' database with the quota on store size which is exceeded
+func stubExceededQuota(client *recordingClient) {
+	client.responses["/dbaas_opensearch_metadata/_search"] =
+		`{"hits":{"hits":[{"_id":"stubprefix","_source":{"microserviceName":"stub-service","quota":{"maxStoreBytes":100000}}},{"_id":"testme","_source":{"text":"check"}}]}}`
+	client.responses[http.MethodGet+" /dbaas_opensearch_metadata/_doc/stubprefix"] =
+		`{"found":true,"_source":{"microserviceName":"stub-service","quota":{"maxStoreBytes":100000}}}`
+}
+
+func TestSetQuota(t *testing.T) {
+	provider, client := newTestProvider()
+	status, err := provider.setQuota("stubprefix", Quota{MaxIndices: 5}, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, QuotaUsage{Indices: 2, PrimaryShards: 4, StoreBytes: 215040}, status.Usage)
+	assert.False(t, status.Exceeded)
+	assert.Empty(t, client.patches)
+
+	status, err = provider.setQuota("stubprefix", Quota{MaxIndices: 2}, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Quota{MaxIndices: 2}, status.Quota)
+	assert.True(t, status.Exceeded)
+	require.Len(t, client.patches, 1)
+	assert.ElementsMatch(t, []Change{
+		{Operation: "add", Path: "/stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1/backend_roles",
+			Value: []interface{}{"dbaas_admin_quota_exceeded"}},
+		{Operation: "add", Path: "/stubprefix_c3e5a7c9e1f34b5d7f9b1d3f5b7d9f1b/backend_roles",
+			Value: []interface{}{"dbaas_ism_quota_exceeded"}},
+	}, client.patches[0])
+}
+
+func TestEnforceQuotas(t *testing.T) {
+	provider, recordingClient := newTestProvider()
+	stubExceededQuota(recordingClient)
+	provider.EnforceQuotas(ctx)
+	require.Len(t, recordingClient.patches, 1)
+	assert.Len(t, recordingClient.patches[0], 2)
+
+	descriptions, err := provider.describeDatabases([]string{"stubprefix"}, ctx)
+	require.NoError(t, err)
+	require.NotNil(t, descriptions["stubprefix"].Quota)
+	assert.Equal(t, QuotaStatus{
+		Quota:    Quota{MaxStoreBytes: 100000},
+		Usage:    QuotaUsage{Indices: 2, PrimaryShards: 4, StoreBytes: 215040},
+		Exceeded: true,
+	}, *descriptions["stubprefix"].Quota)
+}
+
+// stubRestrictedUsers returns users of 'stubprefix' database whose backend roles are restricted by quota
+func stubRestrictedUsers(client *recordingClient) {
+	client.responses[http.MethodGet+" /_plugins/_security/api/internalusers"] = `{` +
+		`"stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1":{"backend_roles":["dbaas_admin_quota_exceeded"],"attributes":{"resource_prefix":"stubprefix"}},` +
+		`"stubprefix_9f1e7c3a2b4d4f6e8a0c5b7d9e1f3a5c":{"backend_roles":["dbaas_readonly"],"attributes":{"resource_prefix":"stubprefix"}},` +
+		`"stubprefix_b2d4f6a8c0e24a6c8e0a2c4e6a8c0e2a":{"backend_roles":["dbaas_dml"],"attributes":{"resource_prefix":"stubprefix"}},` +
+		`"stubprefix_c3e5a7c9e1f34b5d7f9b1d3f5b7d9f1b":{"backend_roles":["dbaas_ism_quota_exceeded"],"attributes":{"resource_prefix":"stubprefix"}}}`
+}
+
+func TestCreateDatabaseWithExistingClassifierAndExceededQuota(t *testing.T) {
+	provider, client := newTestProvider()
+	stubRestrictedUsers(client)
+	request := DbCreateRequest{
+		Metadata: map[string]interface{}{
+			"classifier": map[string]interface{}{"microserviceName": "stub-service", "namespace": "stub-namespace"},
+		},
+		Settings: Settings{ResourcePrefix: true},
+	}
+	r, err := provider.createDatabase(request, ctx)
+	require.NoError(t, err)
+	response := r.(DbCreateResponseMultiUser)
+	require.Len(t, response.ConnectionProperties, 4)
+	for _, connection := range response.ConnectionProperties {
+		assert.Equal(t, "stubprefix", connection.ResourcePrefix)
+		if connection.Role == AdminRoleType {
+			assert.Equal(t, "stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1", connection.Username)
+		}
+	}
+	for _, request := range client.requests {
+		assert.False(t, strings.HasPrefix(request, http.MethodPut+" /_plugins/_security/api/internalusers/"),
+			"user is created again by %s", request)
+	}
+}
+
+func TestWriteUsersKeepsQuotaRestriction(t *testing.T) {
+	provider, client := newTestProvider()
+	stubExceededQuota(client)
+	err := provider.PatchUser("stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1", "", "stubprefix*", AdminRoleType, ctx)
+	require.NoError(t, err)
+	require.Len(t, client.patches, 1)
+	assert.Contains(t, client.patches[0], Change{Operation: "add", Path: "/backend_roles",
+		Value: []interface{}{"dbaas_admin_quota_exceeded"}})
+
+	exceeded, err := provider.exceededQuotas(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"stubprefix": true}, exceeded)
+	content := provider.getUserContent(common.ConnectionProperties{ResourcePrefix: "stubprefix", Role: AdminRoleType}, exceeded)
+	assert.Equal(t, []string{"dbaas_admin_quota_exceeded"}, content.BackendRoles)
+	content = provider.getUserContent(common.ConnectionProperties{ResourcePrefix: "testme", Role: AdminRoleType}, exceeded)
+	assert.Equal(t, []string{"dbaas_admin"}, content.BackendRoles)
+}
+
+func TestQuotaBackendRoles(t *testing.T) {
+	restricted := bp.quotaBackendRoles([]string{"dbaas_admin", "dbaas_ism", "dbaas_dml"}, true)
+	assert.Equal(t, []string{"dbaas_admin_quota_exceeded", "dbaas_ism_quota_exceeded", "dbaas_dml"}, restricted)
+	assert.Equal(t, restricted, bp.quotaBackendRoles(restricted, true))
+	assert.Equal(t, []string{"dbaas_admin", "dbaas_ism", "dbaas_dml"}, bp.quotaBackendRoles(restricted, false))
+	assert.Equal(t, IsmRoleType, bp.roleTypeByBackendRoles([]string{"dbaas_ism_quota_exceeded"}))
+}
+
+func TestSetQuotaHandler(t *testing.T) {
+	provider, _ := newTestProvider()
+	request := httptest.NewRequest(http.MethodPut, "/databases/stubprefix/quota",
+		strings.NewReader(`{"maxIndices":-1,"maxShards":10}`))
+	request = mux.SetURLVars(request, map[string]string{"prefix": "stubprefix"})
+	recorder := httptest.NewRecorder()
+	provider.SetQuotaHandler()(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), common.ValidationErrorCode)
+	assert.Contains(t, recorder.Body.String(), "maxIndices")
+	assert.Contains(t, recorder.Body.String(), "maxShards")
+
+	request = httptest.NewRequest(http.MethodPut, "/databases/stubprefix/quota", strings.NewReader(`{"maxPrimaryShards":10}`))
+	request = mux.SetURLVars(request, map[string]string{"prefix": "stubprefix"})
+	recorder = httptest.NewRecorder()
+	provider.SetQuotaHandler()(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"exceeded":false`)
+}
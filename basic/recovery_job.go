@@ -0,0 +1,261 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+// RecoveryJobsIndex keeps users recovery jobs, passwords of recovered users are never stored there
+const RecoveryJobsIndex = "dbaas_opensearch_recovery_jobs"
+
+const (
+	UserRestoredStatus = "restored"
+	UserFailedStatus   = "failed"
+)
+
+// RecoveryJob is one run of users recovery.
+type RecoveryJob struct {
+	Id               string     `json:"id"`
+	State            string     `json:"state"`
+	StartedAt        time.Time  `json:"startedAt"`
+	FinishedAt       *time.Time `json:"finishedAt,omitempty"`
+	TotalUsers       int        `json:"totalUsers"`
+	TotalBatches     int        `json:"totalBatches"`
+	CompletedBatches int        `json:"completedBatches"`
+	RestoredUsers    int        `json:"restoredUsers"`
+	FailedUsers      int        `json:"failedUsers"`
+	// ResumeOffset is the number of users from the beginning of the request which are already processed
+	ResumeOffset int `json:"resumeOffset"`
+	// UsersDigest identifies the list of recovered users to make sure that the same users are passed on resume
+	UsersDigest string                `json:"usersDigest"`
+	Users       []UserRecoveryOutcome `json:"users,omitempty"`
+	Errors      []string              `json:"errors,omitempty"`
+}
+
+// UserRecoveryOutcome is the result of recovery of one user.
+type UserRecoveryOutcome struct {
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+type recoveryJobSearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source RecoveryJob `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func newRecoveryJob(connectionProperties []common.ConnectionProperties) *RecoveryJob {
+	return &RecoveryJob{
+		Id:           common.GenerateUUID(),
+		State:        RecoveryRunningState,
+		StartedAt:    time.Now().UTC(),
+		TotalUsers:   len(connectionProperties),
+		TotalBatches: (len(connectionProperties) + batchSize - 1) / batchSize,
+		UsersDigest:  usersDigest(connectionProperties),
+	}
+}
+
+// usersDigest is calculated by names of users only, passwords are not involved.
+func usersDigest(connectionProperties []common.ConnectionProperties) string {
+	hash := sha256.New()
+	for _, properties := range connectionProperties {
+		hash.Write([]byte(properties.Username))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// checkResumable verifies that the job was interrupted or has failed users and the same users are requested to recover.
+func (job *RecoveryJob) checkResumable(connectionProperties []common.ConnectionProperties) error {
+	if job == nil {
+		return common.NewNotFoundError(common.NotFoundCode, errors.New("there is no users recovery job to resume"))
+	}
+	if job.State != RecoveryFailedState || job.ResumeOffset >= job.TotalUsers && job.FailedUsers == 0 {
+		return common.NewConflictError(common.RecoveryNotResumableCode,
+			fmt.Errorf("users recovery '%s' in '%s' state with %d of %d processed users and %d failed users cannot be resumed",
+				job.Id, job.State, job.ResumeOffset, job.TotalUsers, job.FailedUsers))
+	}
+	if job.UsersDigest != usersDigest(connectionProperties) {
+		return common.NewConflictError(common.RecoveryNotResumableCode,
+			fmt.Errorf("users differ from the ones of users recovery '%s', it cannot be resumed", job.Id))
+	}
+	return nil
+}
+
+func (job *RecoveryJob) addOutcome(outcome UserRecoveryOutcome) {
+	job.Users = append(job.Users, outcome)
+	if outcome.Status == UserRestoredStatus {
+		job.RestoredUsers++
+	} else {
+		job.FailedUsers++
+	}
+}
+
+// replaceOutcomes replaces outcomes of users which are processed again
+func (job *RecoveryJob) replaceOutcomes(outcomes []UserRecoveryOutcome) {
+	replaced := make(map[string]UserRecoveryOutcome, len(outcomes))
+	for _, outcome := range outcomes {
+		replaced[outcome.Username] = outcome
+	}
+	for i, previous := range job.Users {
+		outcome, ok := replaced[previous.Username]
+		if !ok {
+			continue
+		}
+		if previous.Status == UserRestoredStatus {
+			job.RestoredUsers--
+		} else {
+			job.FailedUsers--
+		}
+		job.Users[i] = outcome
+		if outcome.Status == UserRestoredStatus {
+			job.RestoredUsers++
+		} else {
+			job.FailedUsers++
+		}
+	}
+}
+
+// failedUsers returns names of users which are not restored by the job
+func (job *RecoveryJob) failedUsers() map[string]bool {
+	failed := make(map[string]bool)
+	for _, outcome := range job.Users {
+		if outcome.Status == UserFailedStatus {
+			failed[outcome.Username] = true
+		}
+	}
+	return failed
+}
+
+func (job *RecoveryJob) finish(state string) {
+	finishedAt := time.Now().UTC()
+	job.State = state
+	job.FinishedAt = &finishedAt
+}
+
+func (job *RecoveryJob) copy() *RecoveryJob {
+	jobCopy := *job
+	jobCopy.Users = append([]UserRecoveryOutcome(nil), job.Users...)
+	jobCopy.Errors = append([]string(nil), job.Errors...)
+	return &jobCopy
+}
+
+func (bp *BaseProvider) GetRecoveryJobHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.PrepareContext(r)
+		job := bp.getRecoveryJob()
+		if job == nil {
+			common.WriteError(w, common.NewNotFoundError(common.NotFoundCode, errors.New("users recovery has not been run yet")), ctx)
+			return
+		}
+		responseBody, err := json.Marshal(job)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}
+}
+
+// LoadRecoveryJob restores the latest users recovery job from the index. The job which is still running
+// according to the index was interrupted by restart, so it is marked as failed.
+func (bp *BaseProvider) LoadRecoveryJob(ctx context.Context) {
+	size := 1
+	searchRequest := opensearchapi.SearchRequest{
+		Index: []string{RecoveryJobsIndex},
+		Size:  &size,
+		Sort:  []string{"startedAt:desc"},
+	}
+	var response recoveryJobSearchResponse
+	if err := common.DoRequest(searchRequest, bp.opensearch.Client, &response, ctx); err != nil {
+		logger.ErrorContext(ctx, "Failed to load the latest users recovery job", slog.Any("error", err))
+		return
+	}
+	if len(response.Hits.Hits) == 0 {
+		return
+	}
+	job := response.Hits.Hits[0].Source
+	if job.State == RecoveryRunningState {
+		job.finish(RecoveryFailedState)
+		job.Errors = append(job.Errors, "users recovery is interrupted by restart of the adapter")
+		bp.saveRecoveryJob(&job, ctx)
+	}
+	bp.recoveryMutex.Lock()
+	defer bp.recoveryMutex.Unlock()
+	if bp.recoveryJob == nil {
+		bp.recoveryJob = &job
+		bp.setRecoveryState(job.State)
+	}
+}
+
+func (bp *BaseProvider) getRecoveryJob() *RecoveryJob {
+	bp.recoveryMutex.Lock()
+	defer bp.recoveryMutex.Unlock()
+	if bp.recoveryJob == nil {
+		return nil
+	}
+	return bp.recoveryJob.copy()
+}
+
+// updateRecoveryJob changes the current job under lock and saves its copy to the index.
+func (bp *BaseProvider) updateRecoveryJob(update func(job *RecoveryJob), ctx context.Context) {
+	bp.recoveryMutex.Lock()
+	update(bp.recoveryJob)
+	bp.setRecoveryState(bp.recoveryJob.State)
+	job := bp.recoveryJob.copy()
+	bp.recoveryMutex.Unlock()
+	bp.saveRecoveryJob(job, ctx)
+}
+
+// saveRecoveryJob stores the job in the index, failures are only logged to not interrupt the recovery itself.
+func (bp *BaseProvider) saveRecoveryJob(job *RecoveryJob, ctx context.Context) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("Failed to serialize '%s' users recovery job", job.Id), slog.Any("error", err))
+		return
+	}
+	indexRequest := opensearchapi.IndexRequest{
+		Index:      RecoveryJobsIndex,
+		DocumentID: job.Id,
+		Body:       strings.NewReader(string(body)),
+	}
+	response, err := indexRequest.Do(context.Background(), bp.opensearch.Client)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("Failed to save '%s' users recovery job", job.Id), slog.Any("error", err))
+		return
+	}
+	defer response.Body.Close()
+	if response.IsError() {
+		logger.ErrorContext(ctx, fmt.Sprintf("Failed to save '%s' users recovery job: %s", job.Id, response.String()))
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryJobProgress(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	connectionProperties := recoveredUsers(150)
+	job, err := provider.startRecovery(connectionProperties, false, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, RecoveryRunningState, job.State)
+	assert.Equal(t, 150, job.TotalUsers)
+	assert.Equal(t, 2, job.TotalBatches)
+	provider.recoveryGroup.Wait()
+
+	finished := provider.getRecoveryJob()
+	assert.Equal(t, job.Id, finished.Id)
+	assert.Equal(t, RecoveryDoneState, finished.State)
+	assert.Equal(t, 2, finished.CompletedBatches)
+	assert.Equal(t, 150, finished.RestoredUsers)
+	assert.Zero(t, finished.FailedUsers)
+	assert.Equal(t, 150, finished.ResumeOffset)
+	assert.Len(t, finished.Users, 150)
+	assert.NotNil(t, finished.FinishedAt)
+	assert.Equal(t, RecoveryDoneState, provider.getRecoveryState())
+}
+
+func TestRecoveryIsNotStartedTwice(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	running := newRecoveryJob(recoveredUsers(10))
+	provider.recoveryJob = running
+	job, err := provider.startRecovery([]common.ConnectionProperties{{Username: "recovereduser", Password: "password"}}, false, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, running.Id, job.Id)
+	assert.Equal(t, 10, job.TotalUsers)
+}
+
+func TestLoadInterruptedRecoveryJob(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	assert.Equal(t, RecoveryIdleState, provider.getRecoveryState())
+	provider.LoadRecoveryJob(ctx)
+	job := provider.getRecoveryJob()
+	require.NotNil(t, job)
+	assert.Equal(t, "stubjob", job.Id)
+	assert.Equal(t, RecoveryFailedState, job.State)
+	assert.Equal(t, 100, job.RestoredUsers)
+	assert.NotNil(t, job.FinishedAt)
+	assert.NotEmpty(t, job.Errors)
+	assert.Equal(t, RecoveryFailedState, provider.getRecoveryState())
+}
+
+func TestGetRecoveryJobHandler(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	recorder := httptest.NewRecorder()
+	provider.GetRecoveryJobHandler()(recorder, httptest.NewRequest(http.MethodGet, "/users/restore-password/job", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	provider.recoveryJob = newRecoveryJob(recoveredUsers(250))
+	recorder = httptest.NewRecorder()
+	provider.GetRecoveryJobHandler()(recorder, httptest.NewRequest(http.MethodGet, "/users/restore-password/job", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var job RecoveryJob
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+	assert.Equal(t, provider.recoveryJob.Id, job.Id)
+	assert.Equal(t, 3, job.TotalBatches)
+
+	recorder = httptest.NewRecorder()
+	provider.GetRecoveryStateHandler()(recorder, httptest.NewRequest(http.MethodGet, "/users/restore-password/state", nil))
+	assert.Equal(t, RecoveryRunningState, recorder.Body.String())
+}
+
+func TestRecoveryIsolatesFailedUsers(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	connectionProperties := recoveredUsers(150)
+	connectionProperties[30].Username = common.FailingUsername + "30"
+	connectionProperties[120].Username = common.FailingUsername + "120"
+	_, err := provider.startRecovery(connectionProperties, false, ctx)
+	require.NoError(t, err)
+	provider.recoveryGroup.Wait()
+
+	job := provider.getRecoveryJob()
+	assert.Equal(t, RecoveryFailedState, job.State)
+	assert.Equal(t, 2, job.CompletedBatches)
+	assert.Equal(t, 148, job.RestoredUsers)
+	assert.Equal(t, 2, job.FailedUsers)
+	assert.Len(t, job.Errors, 2)
+	require.Len(t, job.Users, 150)
+	var failed []string
+	for _, outcome := range job.Users {
+		if outcome.Status == UserFailedStatus {
+			failed = append(failed, outcome.Username)
+			assert.NotEmpty(t, outcome.Error)
+		}
+	}
+	assert.Equal(t, []string{common.FailingUsername + "30", common.FailingUsername + "120"}, failed)
+}
+
+func TestResumeRecovery(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	connectionProperties := recoveredUsers(250)
+	interrupted := newRecoveryJob(connectionProperties)
+	interrupted.CompletedBatches = 1
+	interrupted.RestoredUsers = 100
+	interrupted.ResumeOffset = 100
+	interrupted.finish(RecoveryFailedState)
+	provider.recoveryJob = interrupted
+
+	job, err := provider.startRecovery(connectionProperties, true, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, interrupted.Id, job.Id)
+	assert.Equal(t, RecoveryRunningState, job.State)
+	assert.Nil(t, job.FinishedAt)
+	provider.recoveryGroup.Wait()
+
+	finished := provider.getRecoveryJob()
+	assert.Equal(t, RecoveryDoneState, finished.State)
+	assert.Equal(t, 3, finished.CompletedBatches)
+	assert.Equal(t, 250, finished.RestoredUsers)
+	assert.Equal(t, 250, finished.ResumeOffset)
+	require.Len(t, finished.Users, 150)
+	assert.Equal(t, "recovereduser100", finished.Users[0].Username)
+}
+
+func TestResumeRecoveryRetriesFailedUsers(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	connectionProperties := recoveredUsers(150)
+	interrupted := newRecoveryJob(connectionProperties)
+	for i, properties := range connectionProperties {
+		outcome := UserRecoveryOutcome{Username: properties.Username, Status: UserRestoredStatus}
+		if i >= 100 && i < 110 {
+			outcome = UserRecoveryOutcome{Username: properties.Username, Status: UserFailedStatus, Error: "connection refused"}
+		}
+		interrupted.addOutcome(outcome)
+	}
+	interrupted.CompletedBatches = 2
+	interrupted.ResumeOffset = 150
+	interrupted.finish(RecoveryFailedState)
+	provider.recoveryJob = interrupted
+
+	_, err := provider.startRecovery(connectionProperties, true, ctx)
+	require.NoError(t, err)
+	provider.recoveryGroup.Wait()
+
+	finished := provider.getRecoveryJob()
+	assert.Equal(t, RecoveryDoneState, finished.State)
+	assert.Equal(t, 2, finished.CompletedBatches)
+	assert.Equal(t, 150, finished.RestoredUsers)
+	assert.Zero(t, finished.FailedUsers)
+	require.Len(t, finished.Users, 150)
+	assert.Equal(t, UserRecoveryOutcome{Username: "recovereduser105", Status: UserRestoredStatus}, finished.Users[105])
+}
+
+func TestResumeRecoveryIsRejected(t *testing.T) {
+	provider := NewBaseProvider(opensearchConfiguration)
+	connectionProperties := recoveredUsers(250)
+	_, err := provider.startRecovery(connectionProperties, true, ctx)
+	assertErrorStatus(t, http.StatusNotFound, err)
+
+	interrupted := newRecoveryJob(connectionProperties)
+	interrupted.ResumeOffset = 100
+	interrupted.finish(RecoveryFailedState)
+	provider.recoveryJob = interrupted
+	_, err = provider.startRecovery(recoveredUsers(200), true, ctx)
+	assertErrorStatus(t, http.StatusConflict, err)
+
+	provider.recoveryJob.finish(RecoveryDoneState)
+	_, err = provider.startRecovery(connectionProperties, true, ctx)
+	assertErrorStatus(t, http.StatusConflict, err)
+	assert.Equal(t, RecoveryDoneState, provider.getRecoveryJob().State)
+
+	// all users are processed and none of them failed
+	provider.recoveryJob.ResumeOffset = 250
+	provider.recoveryJob.finish(RecoveryFailedState)
+	_, err = provider.startRecovery(connectionProperties, true, ctx)
+	assertErrorStatus(t, http.StatusConflict, err)
+}
+
+func recoveredUsers(count int) []common.ConnectionProperties {
+	var connectionProperties []common.ConnectionProperties
+	for i := 0; i < count; i++ {
+		connectionProperties = append(connectionProperties, common.ConnectionProperties{
+			Username:       fmt.Sprintf("recovereduser%d", i),
+			Password:       "password",
+			ResourcePrefix: "recoveredprefix",
+			Role:           AdminRoleType,
+		})
+	}
+	return connectionProperties
+}
+
+func assertErrorStatus(t *testing.T, status int, err error) {
+	var apiErr *common.Error
+	require.True(t, errors.As(err, &apiErr), "unexpected error %v", err)
+	assert.Equal(t, status, apiErr.Status)
+}
@@ -43,6 +43,8 @@ const (
 	IndicesIsmManagedIndexPermission       = "indices:admin/opensearch/ism/managedindex"
 	IndicesAllActionPermission             = "indices_all"
 	IndicesDeletePermission                = "indices:admin/delete"
+	IndicesCreatePermission                = "indices:admin/create"
+	IndicesResizePermission                = "indices:admin/resize"
 	IndicesRolloverPermission              = "indices:admin/rollover"
 	IndicesMonitorStatsPermission          = "indices:monitor/stats"
 	IndicesDMLActionPermission             = "indices:data/*"
@@ -50,25 +52,52 @@ const (
 	IndicesROActionPermission              = "indices:data/read/*"
 	IndicesExistPermission                 = "indices:admin/exists"
 	IndicesGetPermission                   = "indices:admin/get"
+	IndicesMonitorPermissions              = "indices:monitor/*"
+	IndicesAdminAliasesPermissions         = "indices:admin/aliases*"
+	IndicesMappingsGetPermission           = "indices:admin/mappings/get"
+	IndicesSettingsPermissions             = "indices:admin/settings/*"
+	IndicesRefreshPermissions              = "indices:admin/refresh*"
+	IndicesFlushPermissions                = "indices:admin/flush*"
+	IndicesForceMergePermissions           = "indices:admin/forcemerge*"
+	IndicesClosePermissions                = "indices:admin/close*"
+	IndicesOpenPermission                  = "indices:admin/open"
 	AdminRoleType                          = "admin"
 	DmlRoleType                            = "dml"
 	ReadOnlyRoleType                       = "readonly"
 	IsmRoleType                            = "ism"
 	BackendRolePattern                     = "dbaas_%s"
+	// QuotaExceededAdminRoleType is granted instead of admin role to users of databases which reached their quota
+	QuotaExceededAdminRoleType = AdminRoleType + quotaExceededSuffix
 )
 
 type Role struct {
-	ClusterPermissions []string          `json:"cluster_permissions,omitempty"`
-	IndexPermissions   []IndexPermission `json:"index_permissions"`
+	Description        string             `json:"description,omitempty"`
+	ClusterPermissions []string           `json:"cluster_permissions,omitempty"`
+	IndexPermissions   []IndexPermission  `json:"index_permissions"`
+	TenantPermissions  []TenantPermission `json:"tenant_permissions,omitempty"`
 }
 
 type IndexPermission struct {
 	IndexPatterns  []string `json:"index_patterns"`
+	DLS            string   `json:"dls,omitempty"`
+	FLS            []string `json:"fls,omitempty"`
+	MaskedFields   []string `json:"masked_fields,omitempty"`
 	AllowedActions []string `json:"allowed_actions"`
 }
 
+type TenantPermission struct {
+	TenantPatterns []string `json:"tenant_patterns"`
+	AllowedActions []string `json:"allowed_actions"`
+}
+
+// GetSupportedRoleTypes returns names of configured role types.
 func (bp BaseProvider) GetSupportedRoleTypes() []string {
-	return []string{ReadOnlyRoleType, DmlRoleType, AdminRoleType, IsmRoleType}
+	roleTypes := bp.GetRoleTypes()
+	names := make([]string, 0, len(roleTypes))
+	for _, roleType := range roleTypes {
+		names = append(names, roleType.Name)
+	}
+	return names
 }
 
 func (bp BaseProvider) DefineRoleType(roleName string) string {
@@ -85,92 +114,29 @@ func (bp BaseProvider) GetBackendRoles(roleType string) []string {
 }
 
 func (bp BaseProvider) CreateRoleWithISMPermissions(enhancedSecurityPluginEnabled bool) error {
-	clusterPermissions := []string{
-		ClusterAdminIsmPermissions,
-	}
-	indexGlobalPermissions := []string{
-		IndicesIsmManagedIndexPermission,
-	}
-	if !enhancedSecurityPluginEnabled {
-		indexGlobalPermissions = append(indexGlobalPermissions,
-			IndicesMonitorStatsPermission,
-			IndicesRolloverPermission,
-			IndicesDeletePermission)
-	}
-	return bp.createRole(clusterPermissions, []string{}, indexGlobalPermissions, IsmRoleType)
+	return bp.CreateRoleType(ismRoleTypeDefinition(enhancedSecurityPluginEnabled))
 }
 
 func (bp BaseProvider) CreateRoleWithAdminPermissions() error {
-	indexPermissions := []string{
-		IndicesAllActionPermission,
-		strings.ToUpper(IndicesAllActionPermission),
-	}
-	clusterPermissions := []string{
-		ClusterReadWritePermissions,
-		strings.ToUpper(ClusterReadWritePermissions),
-		ClusterMonitorMainPermission,
-		ClusterMonitorHealthPermission,
-		ClusterMonitorTaskPermissions,
-		ClusterMonitorStatePermission,
-		ClusterScrollClearPermission,
-		ClusterManageIndexTemplatesPermissions,
-		ClusterManageTemplatePermissions,
-		ClusterManageIndexTemplatePermissions,
-	}
-	// IndexGlobalPermissions `indices:admin/resize` permission required for clone index and should be removed after fix https://github.com/opensearch-project/security/issues/429
-	indexGlobalPermissions := []string{
-		ClusterManageIndexTemplatePermissions,
-		ClusterManageAliasesPermissions,
-		"indices:admin/resize",
-	}
-	return bp.createRole(clusterPermissions, indexPermissions, indexGlobalPermissions, AdminRoleType)
+	return bp.CreateRoleType(adminRoleTypeDefinition())
+}
+
+// CreateRoleWithQuotaExceededAdminPermissions creates the role with admin permissions except creation of indices.
+func (bp BaseProvider) CreateRoleWithQuotaExceededAdminPermissions() error {
+	return bp.CreateRoleType(bp.quotaExceededAdminRoleTypeDefinition())
 }
 
 func (bp BaseProvider) CreateRoleWithDMLPermissions() error {
-	indexPermissions := []string{
-		IndicesDMLActionPermission,
-		strings.ToUpper(IndicesDMLActionPermission),
-		IndicesMappingPutPermission,
-		strings.ToUpper(IndicesMappingPutPermission),
-		IndicesExistPermission,
-		strings.ToUpper(IndicesExistPermission),
-		IndicesGetPermission,
-		strings.ToUpper(IndicesGetPermission),
-	}
-	clusterPermissions := []string{
-		ClusterReadWritePermissions,
-		strings.ToUpper(ClusterReadWritePermissions),
-		ClusterScrollClearPermission,
-		ClusterMonitorTaskGetPermission,
-		ClusterMonitorStatePermission,
-		ClusterMonitorMainPermission,
-	}
-	return bp.createRole(clusterPermissions, indexPermissions, []string{}, DmlRoleType)
+	return bp.CreateRoleType(dmlRoleTypeDefinition())
 }
 
 func (bp BaseProvider) CreateRoleWithReadOnlyPermissions() error {
-	indexPermissions := []string{
-		IndicesROActionPermission,
-		IndicesExistPermission,
-		IndicesGetPermission,
-		strings.ToUpper(IndicesROActionPermission),
-		strings.ToUpper(IndicesExistPermission),
-		strings.ToUpper(IndicesGetPermission),
-	}
-	clusterPermissions := []string{
-		ClusterReadOnlyPermissions,
-		strings.ToUpper(ClusterReadOnlyPermissions),
-		ClusterScrollClearPermission,
-		ClusterMonitorStatePermission,
-		ClusterMonitorMainPermission,
-	}
-	return bp.createRole(clusterPermissions, indexPermissions, []string{}, ReadOnlyRoleType)
+	return bp.CreateRoleType(readOnlyRoleTypeDefinition())
 }
 
-func (bp BaseProvider) createRole(clusterPermissions []string, indexPermissions []string,
-	globalIndexPermissions []string, roleType string) error {
-	name := fmt.Sprintf(common.RoleNamePattern, roleType)
-	logger.Debug(fmt.Sprintf("Creating role with name [%s]", name))
+// newRole returns the role with index permissions on indices which start with resource prefix of the user
+// and global index permissions on all indices.
+func newRole(clusterPermissions []string, indexPermissions []string, globalIndexPermissions []string) Role {
 	role := Role{
 		ClusterPermissions: clusterPermissions,
 		IndexPermissions: []IndexPermission{
@@ -186,6 +152,17 @@ func (bp BaseProvider) createRole(clusterPermissions []string, indexPermissions
 			AllowedActions: globalIndexPermissions,
 		})
 	}
+	return role
+}
+
+func (bp BaseProvider) createRole(clusterPermissions []string, indexPermissions []string,
+	globalIndexPermissions []string, roleType string) error {
+	name := fmt.Sprintf(common.RoleNamePattern, roleType)
+	logger.Debug(fmt.Sprintf("Creating role with name [%s]", name))
+	return bp.putRole(name, newRole(clusterPermissions, indexPermissions, globalIndexPermissions))
+}
+
+func (bp BaseProvider) putRole(name string, role Role) error {
 	body, err := json.Marshal(role)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to marshal body for '%s' role", name))
@@ -0,0 +1,183 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/dbaas-opensearch-adapter/metrics"
+)
+
+const (
+	// RoleDriftKind is the kind of drift of permissions of the role
+	RoleDriftKind = "role"
+	// RoleMappingDriftKind is the kind of drift of users, hosts or backend roles of the role mapping
+	RoleMappingDriftKind = "role_mapping"
+)
+
+// RoleDrift is a difference of the role or the role mapping in OpenSearch from the state created by the adapter.
+type RoleDrift struct {
+	// Kind is either RoleDriftKind or RoleMappingDriftKind
+	Kind string
+	// Name is the name of the role, the role mapping has the same name
+	Name string
+	// Repaired means that the desired state is applied, it is always false in report-only mode
+	Repaired bool
+}
+
+// StartRoleReconciler checks roles and role mappings on start and then with the given interval until shutdown.
+// Drifts are only logged and counted in metrics if reportOnly is enabled, otherwise they are repaired as described
+// in ReconcileRoles. Errors of the check are logged and the next check is performed on schedule.
+func (bp *BaseProvider) StartRoleReconciler(interval time.Duration, reportOnly bool) {
+	bp.runPeriodically(interval, func() {
+		ctx := context.Background()
+		if _, err := bp.ReconcileRoles(reportOnly, ctx); err != nil {
+			logger.ErrorContext(ctx, "Failed to reconcile roles", slog.Any("error", err))
+		}
+	})
+}
+
+// ReconcileRoles compares roles and role mappings of managed role types with their desired state and re-applies
+// the desired state of drifted ones unless reportOnly is enabled. Missing roles and mappings are drifts as well.
+// Drifted mapping is overwritten to exactly desiredRoleMapping, so users, hosts and backend roles added to it
+// by hand are removed. In report-only mode nothing is changed in OpenSearch, found drifts are returned not repaired.
+// Checks of other roles continue if one of them fails, all errors are returned joined.
+func (bp BaseProvider) ReconcileRoles(reportOnly bool, ctx context.Context) ([]RoleDrift, error) {
+	var drifts []RoleDrift
+	var errs []error
+	for _, roleType := range bp.managedRoleTypes() {
+		name := fmt.Sprintf(common.RoleNamePattern, roleType.Name)
+		role, err := bp.GetRole(name)
+		if err != nil {
+			errs = append(errs, err)
+		} else if drifted := role == nil || !sameRole(*role, roleType.role()); drifted {
+			drift := RoleDrift{Kind: RoleDriftKind, Name: name}
+			if !reportOnly {
+				if err = bp.CreateRoleType(roleType); err != nil {
+					errs = append(errs, err)
+				}
+				drift.Repaired = err == nil
+			}
+			drifts = append(drifts, bp.reportRoleDrift(drift, ctx))
+		} else {
+			metrics.ObserveRoleDrift(bp.clusterId(), RoleDriftKind, name, false)
+		}
+
+		mapping, err := bp.GetRoleMapping(name)
+		if err != nil {
+			errs = append(errs, err)
+		} else if drifted := mapping == nil || !sameRoleMapping(*mapping, bp.desiredRoleMapping(roleType.Name)); drifted {
+			drift := RoleDrift{Kind: RoleMappingDriftKind, Name: name}
+			if !reportOnly {
+				if err = bp.CreateOrUpdateRoleMapping(roleType.Name); err != nil {
+					errs = append(errs, err)
+				}
+				drift.Repaired = err == nil
+			}
+			drifts = append(drifts, bp.reportRoleDrift(drift, ctx))
+		} else {
+			metrics.ObserveRoleDrift(bp.clusterId(), RoleMappingDriftKind, name, false)
+		}
+	}
+	return drifts, errors.Join(errs...)
+}
+
+// reportRoleDrift marks the role as drifted in metrics and logs whether it is repaired, the drift is returned as is.
+func (bp BaseProvider) reportRoleDrift(drift RoleDrift, ctx context.Context) RoleDrift {
+	metrics.ObserveRoleDrift(bp.clusterId(), drift.Kind, drift.Name, true)
+	kind := "Role"
+	if drift.Kind == RoleMappingDriftKind {
+		kind = "Role mapping"
+	}
+	if drift.Repaired {
+		logger.WarnContext(ctx, fmt.Sprintf("%s '%s' differed from desired state and is restored", kind, drift.Name))
+	} else {
+		logger.WarnContext(ctx, fmt.Sprintf("%s '%s' differs from desired state", kind, drift.Name))
+	}
+	return drift
+}
+
+// sameRole compares permissions of roles regardless of order, index and tenant permissions without actions are ignored.
+func sameRole(actual Role, desired Role) bool {
+	if !sameElements(actual.ClusterPermissions, desired.ClusterPermissions) {
+		return false
+	}
+	actualPermissions := effectivePermissions(actual.IndexPermissions, IndexPermission.actions)
+	desiredPermissions := effectivePermissions(desired.IndexPermissions, IndexPermission.actions)
+	if len(actualPermissions) != len(desiredPermissions) {
+		return false
+	}
+	for i := range desiredPermissions {
+		if !sameElements(actualPermissions[i].IndexPatterns, desiredPermissions[i].IndexPatterns) ||
+			actualPermissions[i].DLS != desiredPermissions[i].DLS ||
+			!sameElements(actualPermissions[i].FLS, desiredPermissions[i].FLS) ||
+			!sameElements(actualPermissions[i].MaskedFields, desiredPermissions[i].MaskedFields) ||
+			!sameElements(actualPermissions[i].AllowedActions, desiredPermissions[i].AllowedActions) {
+			return false
+		}
+	}
+	actualTenantPermissions := effectivePermissions(actual.TenantPermissions, TenantPermission.actions)
+	desiredTenantPermissions := effectivePermissions(desired.TenantPermissions, TenantPermission.actions)
+	if len(actualTenantPermissions) != len(desiredTenantPermissions) {
+		return false
+	}
+	for i := range desiredTenantPermissions {
+		if !sameElements(actualTenantPermissions[i].TenantPatterns, desiredTenantPermissions[i].TenantPatterns) ||
+			!sameElements(actualTenantPermissions[i].AllowedActions, desiredTenantPermissions[i].AllowedActions) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameRoleMapping compares users, hosts and backend roles of role mappings regardless of order
+func sameRoleMapping(actual RoleMapping, desired RoleMapping) bool {
+	return sameElements(actual.Users, desired.Users) &&
+		sameElements(actual.Hosts, desired.Hosts) &&
+		sameElements(actual.BackendRoles, desired.BackendRoles) &&
+		sameElements(actual.AndBackendRoles, desired.AndBackendRoles)
+}
+
+func (p IndexPermission) actions() []string {
+	return p.AllowedActions
+}
+
+func (p TenantPermission) actions() []string {
+	return p.AllowedActions
+}
+
+func effectivePermissions[P any](permissions []P, actions func(P) []string) []P {
+	effective := make([]P, 0, len(permissions))
+	for _, permission := range permissions {
+		if len(actions(permission)) > 0 {
+			effective = append(effective, permission)
+		}
+	}
+	return effective
+}
+
+func sameElements(first []string, second []string) bool {
+	first = slices.Clone(first)
+	second = slices.Clone(second)
+	slices.Sort(first)
+	slices.Sort(second)
+	return slices.Equal(slices.Compact(first), slices.Compact(second))
+}
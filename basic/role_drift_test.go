@@ -0,0 +1,120 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileRoles(t *testing.T) {
+	provider, client := newTestProvider()
+	client.stubSecurity()
+	managed := len(provider.managedRoleTypes())
+
+	drifts, err := provider.ReconcileRoles(true, ctx)
+	require.NoError(t, err)
+	assert.Len(t, drifts, 2*managed)
+	assert.Zero(t, client.puts)
+	assert.False(t, drifts[0].Repaired)
+
+	drifts, err = provider.ReconcileRoles(false, ctx)
+	require.NoError(t, err)
+	assert.Len(t, drifts, 2*managed)
+	assert.True(t, drifts[0].Repaired)
+	assert.Equal(t, 2*managed, client.puts)
+
+	drifts, err = provider.ReconcileRoles(false, ctx)
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	adminRole := fmt.Sprintf(common.RoleNamePattern, AdminRoleType)
+	role := client.roles[adminRole]
+	role.IndexPermissions[0].AllowedActions = []string{IndicesROActionPermission}
+	client.roles[adminRole] = role
+	mapping := client.mappings[adminRole]
+	mapping.BackendRoles = append(mapping.BackendRoles, "dbaas_dml")
+	mapping.Users = []string{"manual"}
+	client.mappings[adminRole] = mapping
+
+	drifts, err = provider.ReconcileRoles(true, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RoleDrift{{Kind: RoleDriftKind, Name: adminRole}, {Kind: RoleMappingDriftKind, Name: adminRole}}, drifts)
+
+	drifts, err = provider.ReconcileRoles(false, ctx)
+	require.NoError(t, err)
+	assert.Len(t, drifts, 2)
+	assert.Equal(t, []string{IndicesAllActionPermission, strings.ToUpper(IndicesAllActionPermission)},
+		client.roles[adminRole].IndexPermissions[0].AllowedActions)
+	assert.Equal(t, RoleMapping{BackendRoles: []string{"dbaas_admin", "dbaas_ism"}}, client.mappings[adminRole])
+
+	mapping = client.mappings[adminRole]
+	mapping.Hosts = []string{"10.0.0.1"}
+	client.mappings[adminRole] = mapping
+	role = client.roles[adminRole]
+	role.TenantPermissions = []TenantPermission{{TenantPatterns: []string{"global_tenant"}, AllowedActions: []string{"kibana_all_write"}}}
+	client.roles[adminRole] = role
+
+	drifts, err = provider.ReconcileRoles(false, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RoleDrift{{Kind: RoleDriftKind, Name: adminRole, Repaired: true},
+		{Kind: RoleMappingDriftKind, Name: adminRole, Repaired: true}}, drifts)
+	assert.Empty(t, client.roles[adminRole].TenantPermissions)
+	assert.Empty(t, client.mappings[adminRole].Hosts)
+}
+
+func TestSameRole(t *testing.T) {
+	desired := ismRoleTypeDefinition(false).role()
+	actual := Role{
+		ClusterPermissions: []string{ClusterAdminIsmPermissions, ClusterAdminIsmPermissions},
+		IndexPermissions: []IndexPermission{{
+			IndexPatterns: []string{AllIndices},
+			AllowedActions: []string{IndicesDeletePermission, IndicesIsmManagedIndexPermission, IndicesRolloverPermission,
+				IndicesMonitorStatsPermission},
+		}},
+	}
+	assert.True(t, sameRole(actual, desired))
+	actual.TenantPermissions = []TenantPermission{{TenantPatterns: []string{"global_tenant"}}}
+	assert.True(t, sameRole(actual, desired))
+
+	for _, change := range []func(role *Role){
+		func(role *Role) {
+			role.ClusterPermissions = append(role.ClusterPermissions, ClusterMonitorStatePermission)
+		},
+		func(role *Role) { role.IndexPermissions[1].DLS = `{"term":{"public":true}}` },
+		func(role *Role) { role.IndexPermissions[1].FLS = []string{"~secret"} },
+		func(role *Role) { role.IndexPermissions[1].MaskedFields = []string{"email"} },
+		func(role *Role) {
+			role.TenantPermissions = []TenantPermission{{TenantPatterns: []string{"global_tenant"}, AllowedActions: []string{"kibana_all_read"}}}
+		},
+	} {
+		changed := ismRoleTypeDefinition(false).role()
+		change(&changed)
+		assert.False(t, sameRole(changed, desired))
+	}
+}
+
+func TestSameRoleMapping(t *testing.T) {
+	desired := RoleMapping{BackendRoles: []string{"dbaas_admin", "dbaas_ism"}}
+	assert.True(t, sameRoleMapping(RoleMapping{BackendRoles: []string{"dbaas_ism", "dbaas_admin"}, Reserved: true}, desired))
+	assert.False(t, sameRoleMapping(RoleMapping{BackendRoles: desired.BackendRoles, Users: []string{"manual"}}, desired))
+	assert.False(t, sameRoleMapping(RoleMapping{BackendRoles: desired.BackendRoles, Hosts: []string{"*"}}, desired))
+	assert.False(t, sameRoleMapping(RoleMapping{BackendRoles: desired.BackendRoles, AndBackendRoles: []string{"dbaas_dml"}}, desired))
+}
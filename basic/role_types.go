@@ -0,0 +1,309 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// roleTypeNamePattern restricts names of role types, because they are used in names of roles and backend roles
+var roleTypeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// RoleTypesConfig is the content of the role types configuration file.
+type RoleTypesConfig struct {
+	RoleTypes []RoleTypeDefinition `json:"roleTypes"`
+}
+
+// RoleTypeDefinition describes the role created for the role type. The role is mapped to `dbaas_<name>` backend role
+// which is granted to users of the role type.
+type RoleTypeDefinition struct {
+	Name               string   `json:"name"`
+	ClusterPermissions []string `json:"clusterPermissions,omitempty"`
+	// IndexPermissions are allowed on indices which start with resource prefix of the user
+	IndexPermissions []string `json:"indexPermissions,omitempty"`
+	// GlobalIndexPermissions are allowed on all indices
+	GlobalIndexPermissions []string `json:"globalIndexPermissions,omitempty"`
+	// Includes lists role types whose roles are also granted to users of this role type
+	Includes []string `json:"includes,omitempty"`
+}
+
+// DefaultRoleTypes returns built-in readonly, dml, admin and ism role types.
+func DefaultRoleTypes(enhancedSecurityPluginEnabled bool) []RoleTypeDefinition {
+	return []RoleTypeDefinition{
+		readOnlyRoleTypeDefinition(),
+		dmlRoleTypeDefinition(),
+		adminRoleTypeDefinition(),
+		ismRoleTypeDefinition(enhancedSecurityPluginEnabled),
+	}
+}
+
+// LoadRoleTypes reads the role types configuration file, built-in role types are returned if the path is empty.
+func LoadRoleTypes(path string, enhancedSecurityPluginEnabled bool) ([]RoleTypeDefinition, error) {
+	if path == "" {
+		return DefaultRoleTypes(enhancedSecurityPluginEnabled), nil
+	}
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read role types configuration file: %w", err)
+	}
+	var config RoleTypesConfig
+	if err = json.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse role types configuration file '%s': %w", path, err)
+	}
+	if err = validateRoleTypes(config.RoleTypes); err != nil {
+		return nil, fmt.Errorf("role types configuration file '%s' is not valid: %w", path, err)
+	}
+	return config.RoleTypes, nil
+}
+
+func validateRoleTypes(roleTypes []RoleTypeDefinition) error {
+	names := make(map[string]bool, len(roleTypes))
+	for _, roleType := range roleTypes {
+		if !roleTypeNamePattern.MatchString(roleType.Name) {
+			return fmt.Errorf("role type '%s' must match '%s' pattern", roleType.Name, roleTypeNamePattern)
+		}
+		if strings.HasSuffix(roleType.Name, quotaExceededSuffix) {
+			return fmt.Errorf("role type '%s' must not end with '%s'", roleType.Name, quotaExceededSuffix)
+		}
+		if names[roleType.Name] {
+			return fmt.Errorf("role type '%s' is not unique", roleType.Name)
+		}
+		names[roleType.Name] = true
+		if len(roleType.ClusterPermissions) == 0 && len(roleType.IndexPermissions) == 0 &&
+			len(roleType.GlobalIndexPermissions) == 0 {
+			return fmt.Errorf("permissions must be specified for '%s' role type", roleType.Name)
+		}
+	}
+	// admin role type is used for users of v1 API and for restriction of databases which reached their quota
+	if !names[AdminRoleType] {
+		return fmt.Errorf("'%s' role type must be specified", AdminRoleType)
+	}
+	for _, roleType := range roleTypes {
+		for _, included := range roleType.Includes {
+			if included == roleType.Name || !names[included] {
+				return fmt.Errorf("'%s' role type includes unknown role type '%s'", roleType.Name, included)
+			}
+		}
+	}
+	return nil
+}
+
+// ConfigureRoleTypes replaces role types of the provider, it must be called before roles are created and handlers
+// are served.
+func (bp *BaseProvider) ConfigureRoleTypes(roleTypes []RoleTypeDefinition) error {
+	if err := validateRoleTypes(roleTypes); err != nil {
+		return fmt.Errorf("invalid role types: %w", err)
+	}
+	bp.roleTypes = roleTypes
+	return nil
+}
+
+// GetRoleTypes returns configured role types, built-in role types are used if they are not configured.
+func (bp BaseProvider) GetRoleTypes() []RoleTypeDefinition {
+	if len(bp.roleTypes) == 0 {
+		return DefaultRoleTypes(false)
+	}
+	return bp.roleTypes
+}
+
+// CreateRoleType creates or updates the role of the role type.
+func (bp BaseProvider) CreateRoleType(roleType RoleTypeDefinition) error {
+	return bp.createRole(roleType.ClusterPermissions, roleType.indexPermissions(), roleType.GlobalIndexPermissions,
+		roleType.Name)
+}
+
+// role returns the role which is created for the role type.
+func (roleType RoleTypeDefinition) role() Role {
+	return newRole(roleType.ClusterPermissions, roleType.indexPermissions(), roleType.GlobalIndexPermissions)
+}
+
+func (roleType RoleTypeDefinition) indexPermissions() []string {
+	if roleType.IndexPermissions == nil {
+		return []string{}
+	}
+	return roleType.IndexPermissions
+}
+
+// managedRoleTypes returns configured role types and the role type for users of databases which reached their quota.
+func (bp BaseProvider) managedRoleTypes() []RoleTypeDefinition {
+	return append(slices.Clone(bp.GetRoleTypes()), bp.quotaExceededAdminRoleTypeDefinition())
+}
+
+// grantees returns role types whose users are granted the role of the given role type.
+func (bp BaseProvider) grantees(roleType string) []string {
+	var grantees []string
+	for _, definition := range bp.GetRoleTypes() {
+		if definition.Name == roleType || slices.Contains(definition.Includes, roleType) {
+			grantees = append(grantees, definition.Name)
+		}
+	}
+	return grantees
+}
+
+func readOnlyRoleTypeDefinition() RoleTypeDefinition {
+	return RoleTypeDefinition{
+		Name: ReadOnlyRoleType,
+		ClusterPermissions: []string{
+			ClusterReadOnlyPermissions,
+			strings.ToUpper(ClusterReadOnlyPermissions),
+			ClusterScrollClearPermission,
+			ClusterMonitorStatePermission,
+			ClusterMonitorMainPermission,
+		},
+		IndexPermissions: []string{
+			IndicesROActionPermission,
+			IndicesExistPermission,
+			IndicesGetPermission,
+			strings.ToUpper(IndicesROActionPermission),
+			strings.ToUpper(IndicesExistPermission),
+			strings.ToUpper(IndicesGetPermission),
+		},
+	}
+}
+
+func dmlRoleTypeDefinition() RoleTypeDefinition {
+	return RoleTypeDefinition{
+		Name: DmlRoleType,
+		ClusterPermissions: []string{
+			ClusterReadWritePermissions,
+			strings.ToUpper(ClusterReadWritePermissions),
+			ClusterScrollClearPermission,
+			ClusterMonitorTaskGetPermission,
+			ClusterMonitorStatePermission,
+			ClusterMonitorMainPermission,
+		},
+		IndexPermissions: []string{
+			IndicesDMLActionPermission,
+			strings.ToUpper(IndicesDMLActionPermission),
+			IndicesMappingPutPermission,
+			strings.ToUpper(IndicesMappingPutPermission),
+			IndicesExistPermission,
+			strings.ToUpper(IndicesExistPermission),
+			IndicesGetPermission,
+			strings.ToUpper(IndicesGetPermission),
+		},
+	}
+}
+
+func adminRoleTypeDefinition() RoleTypeDefinition {
+	return RoleTypeDefinition{
+		Name: AdminRoleType,
+		ClusterPermissions: []string{
+			ClusterReadWritePermissions,
+			strings.ToUpper(ClusterReadWritePermissions),
+			ClusterMonitorMainPermission,
+			ClusterMonitorHealthPermission,
+			ClusterMonitorTaskPermissions,
+			ClusterMonitorStatePermission,
+			ClusterScrollClearPermission,
+			ClusterManageIndexTemplatesPermissions,
+			ClusterManageTemplatePermissions,
+			ClusterManageIndexTemplatePermissions,
+		},
+		IndexPermissions: []string{
+			IndicesAllActionPermission,
+			strings.ToUpper(IndicesAllActionPermission),
+		},
+		// `indices:admin/resize` permission required for clone index and should be removed after fix https://github.com/opensearch-project/security/issues/429
+		GlobalIndexPermissions: []string{
+			ClusterManageIndexTemplatePermissions,
+			ClusterManageAliasesPermissions,
+			IndicesResizePermission,
+		},
+	}
+}
+
+// ismRoleTypeDefinition returns ism role type, its users are also granted admin role
+func ismRoleTypeDefinition(enhancedSecurityPluginEnabled bool) RoleTypeDefinition {
+	globalIndexPermissions := []string{
+		IndicesIsmManagedIndexPermission,
+	}
+	if !enhancedSecurityPluginEnabled {
+		globalIndexPermissions = append(globalIndexPermissions,
+			IndicesMonitorStatsPermission,
+			IndicesRolloverPermission,
+			IndicesDeletePermission)
+	}
+	return RoleTypeDefinition{
+		Name:                   IsmRoleType,
+		ClusterPermissions:     []string{ClusterAdminIsmPermissions},
+		GlobalIndexPermissions: globalIndexPermissions,
+		Includes:               []string{AdminRoleType},
+	}
+}
+
+// quotaExceededIndexPermissions are granted instead of permissions which allow all actions on indices
+// to users of databases which reached their quota, they do not include creation of indices.
+var quotaExceededIndexPermissions = []string{
+	IndicesDMLActionPermission,
+	IndicesMonitorPermissions,
+	IndicesDeletePermission,
+	IndicesExistPermission,
+	IndicesGetPermission,
+	IndicesMappingPutPermission,
+	IndicesMappingsGetPermission,
+	IndicesSettingsPermissions,
+	IndicesAdminAliasesPermissions,
+	IndicesRefreshPermissions,
+	IndicesFlushPermissions,
+	IndicesForceMergePermissions,
+	IndicesClosePermissions,
+	IndicesOpenPermission,
+}
+
+// quotaExceededAdminRoleTypeDefinition returns the role type with permissions of the configured admin role type
+// except creation of indices, permissions which allow all actions on indices are replaced by the list of allowed
+// actions, and `indices:admin/create` and `indices:admin/resize` are not granted.
+func (bp BaseProvider) quotaExceededAdminRoleTypeDefinition() RoleTypeDefinition {
+	admin := adminRoleTypeDefinition()
+	for _, roleType := range bp.GetRoleTypes() {
+		if roleType.Name == AdminRoleType {
+			admin = roleType
+		}
+	}
+	return RoleTypeDefinition{
+		Name:                   QuotaExceededAdminRoleType,
+		ClusterPermissions:     admin.ClusterPermissions,
+		IndexPermissions:       withoutIndexCreation(admin.IndexPermissions),
+		GlobalIndexPermissions: withoutIndexCreation(admin.GlobalIndexPermissions),
+	}
+}
+
+// withoutIndexCreation returns permissions which do not allow creation of indices.
+func withoutIndexCreation(permissions []string) []string {
+	var restricted []string
+	for _, permission := range permissions {
+		var replacement []string
+		switch {
+		case strings.EqualFold(permission, IndicesAllActionPermission) || permission == "*" || permission == "indices:*":
+			replacement = quotaExceededIndexPermissions
+		case permission == IndicesCreatePermission || permission == IndicesResizePermission:
+			continue
+		default:
+			replacement = []string{permission}
+		}
+		for _, allowed := range replacement {
+			if !slices.Contains(restricted, allowed) {
+				restricted = append(restricted, allowed)
+			}
+		}
+	}
+	return restricted
+}
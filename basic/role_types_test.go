@@ -0,0 +1,111 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRoleTypesConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "role-types.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadRoleTypes(t *testing.T) {
+	roleTypes, err := LoadRoleTypes("", true)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRoleTypes(true), roleTypes)
+
+	path := writeRoleTypesConfig(t, `{"roleTypes":[
+		{"name":"admin","clusterPermissions":["cluster_composite_ops"],"indexPermissions":["indices_all"]},
+		{"name":"monitoring","clusterPermissions":["cluster:monitor/*"],"indexPermissions":["indices:monitor/*"]},
+		{"name":"operator","globalIndexPermissions":["indices:admin/opensearch/ism/managedindex"],"includes":["admin","monitoring"]}
+	]}`)
+	roleTypes, err = LoadRoleTypes(path, false)
+	require.NoError(t, err)
+	require.Len(t, roleTypes, 3)
+	assert.Equal(t, []string{"indices:monitor/*"}, roleTypes[1].IndexPermissions)
+	assert.Equal(t, []string{AdminRoleType, "monitoring"}, roleTypes[2].Includes)
+}
+
+func TestLoadInvalidRoleTypes(t *testing.T) {
+	_, err := LoadRoleTypes(filepath.Join(t.TempDir(), "absent.json"), false)
+	assert.ErrorContains(t, err, "failed to read")
+
+	for content, message := range map[string]string{
+		`{"roleTypes":`: "failed to parse",
+		`{"roleTypes":[{"name":"dml","indexPermissions":["indices:data/*"]}]}`:                                                    "'admin' role type must be specified",
+		`{"roleTypes":[{"name":"Admin","indexPermissions":["indices_all"]}]}`:                                                     "must match",
+		`{"roleTypes":[{"name":"admin","indexPermissions":["indices_all"]},{"name":"admin","indexPermissions":["indices_all"]}]}`: "is not unique",
+		`{"roleTypes":[{"name":"admin"}]}`: "permissions must be specified",
+		`{"roleTypes":[{"name":"admin","indexPermissions":["indices_all"],"includes":["ism"]}]}`:                                     "includes unknown role type 'ism'",
+		`{"roleTypes":[{"name":"admin","indexPermissions":["indices_all"]},{"name":"dml_quota_exceeded","indexPermissions":["a"]}]}`: "must not end with",
+	} {
+		_, err = LoadRoleTypes(writeRoleTypesConfig(t, content), false)
+		assert.ErrorContains(t, err, message, content)
+	}
+}
+
+func TestCustomRoleTypes(t *testing.T) {
+	provider := BaseProvider{}
+	assert.Equal(t, []string{ReadOnlyRoleType, DmlRoleType, AdminRoleType, IsmRoleType}, provider.GetSupportedRoleTypes())
+	assert.Equal(t, []string{"dbaas_admin", "dbaas_ism"}, provider.GetBackendRolesForMapping(AdminRoleType))
+	assert.Equal(t, []string{"dbaas_ism", "dbaas_ism_quota_exceeded"}, provider.GetBackendRolesForMapping(IsmRoleType))
+	assert.Equal(t, []string{"dbaas_admin_quota_exceeded", "dbaas_ism_quota_exceeded"},
+		provider.GetBackendRolesForMapping(QuotaExceededAdminRoleType))
+
+	require.NoError(t, provider.ConfigureRoleTypes([]RoleTypeDefinition{
+		{Name: AdminRoleType, IndexPermissions: []string{IndicesAllActionPermission}},
+		{Name: "monitoring", IndexPermissions: []string{IndicesMonitorPermissions}},
+		{Name: "operator", ClusterPermissions: []string{ClusterAdminIsmPermissions}, Includes: []string{AdminRoleType, "monitoring"}},
+	}))
+	assert.Equal(t, []string{AdminRoleType, "monitoring", "operator"}, provider.GetSupportedRoleTypes())
+	assert.Equal(t, []string{"dbaas_admin", "dbaas_operator"}, provider.GetBackendRolesForMapping(AdminRoleType))
+	assert.Equal(t, []string{"dbaas_monitoring", "dbaas_operator", "dbaas_operator_quota_exceeded"},
+		provider.GetBackendRolesForMapping("monitoring"))
+	assert.Equal(t, []string{"dbaas_admin_quota_exceeded", "dbaas_operator_quota_exceeded"},
+		provider.GetBackendRolesForMapping(QuotaExceededAdminRoleType))
+	assert.Equal(t, "operator", provider.roleTypeByBackendRoles([]string{"dbaas_operator_quota_exceeded"}))
+
+	assert.Error(t, provider.ConfigureRoleTypes([]RoleTypeDefinition{{Name: DmlRoleType, IndexPermissions: []string{"a"}}}))
+}
+
+func TestQuotaExceededAdminRoleType(t *testing.T) {
+	provider := BaseProvider{}
+	roleType := provider.quotaExceededAdminRoleTypeDefinition()
+	assert.Equal(t, adminRoleTypeDefinition().ClusterPermissions, roleType.ClusterPermissions)
+	assert.Equal(t, quotaExceededIndexPermissions, roleType.IndexPermissions)
+	assert.Equal(t, []string{ClusterManageIndexTemplatePermissions, ClusterManageAliasesPermissions},
+		roleType.GlobalIndexPermissions)
+
+	require.NoError(t, provider.ConfigureRoleTypes([]RoleTypeDefinition{{
+		Name:                   AdminRoleType,
+		ClusterPermissions:     []string{ClusterReadWritePermissions},
+		IndexPermissions:       []string{IndicesAllActionPermission, IndicesCreatePermission, "indices:admin/analyze"},
+		GlobalIndexPermissions: []string{IndicesResizePermission, IndicesMonitorStatsPermission},
+	}}))
+	roleType = provider.quotaExceededAdminRoleTypeDefinition()
+	assert.Equal(t, QuotaExceededAdminRoleType, roleType.Name)
+	assert.Equal(t, []string{ClusterReadWritePermissions}, roleType.ClusterPermissions)
+	assert.Equal(t, append(slices.Clone(quotaExceededIndexPermissions), "indices:admin/analyze"), roleType.IndexPermissions)
+	assert.Equal(t, []string{IndicesMonitorStatsPermission}, roleType.GlobalIndexPermissions)
+}
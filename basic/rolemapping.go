@@ -21,13 +21,16 @@ import (
 	"github.com/Netcracker/dbaas-opensearch-adapter/api"
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
 	"net/http"
+	"slices"
 	"strings"
 )
 
 type RoleMapping struct {
-	Users        []string `json:"users,omitempty"`
-	Reserved     bool     `json:"reserved,omitempty"`
-	BackendRoles []string `json:"backend_roles,omitempty"`
+	Users           []string `json:"users,omitempty"`
+	Reserved        bool     `json:"reserved,omitempty"`
+	BackendRoles    []string `json:"backend_roles,omitempty"`
+	AndBackendRoles []string `json:"and_backend_roles,omitempty"`
+	Hosts           []string `json:"hosts,omitempty"`
 }
 
 func (bp BaseProvider) createRoleMapping(roleName string, roleMapping RoleMapping) error {
@@ -60,24 +63,39 @@ func (bp BaseProvider) createRoleMapping(roleName string, roleMapping RoleMappin
 	return nil
 }
 
+// CreateOrUpdateRoleMapping writes the desired mapping of the role of the given type, users, hosts and backend roles
+// added to the mapping outside of the adapter are removed.
 func (bp BaseProvider) CreateOrUpdateRoleMapping(roleType string) error {
-	roleName := fmt.Sprintf(common.RoleNamePattern, roleType)
-	roleMapping, err := bp.GetRoleMapping(roleName)
-	if err != nil {
-		return err
-	}
-	if roleMapping == nil {
-		roleMapping = &RoleMapping{}
-	}
-	roleMapping.BackendRoles = bp.GetBackendRolesForMapping(roleType)
-	return bp.createRoleMapping(roleName, *roleMapping)
+	return bp.createRoleMapping(fmt.Sprintf(common.RoleNamePattern, roleType), bp.desiredRoleMapping(roleType))
+}
+
+// desiredRoleMapping returns the mapping of the role of the given type as it is created by the adapter
+func (bp BaseProvider) desiredRoleMapping(roleType string) RoleMapping {
+	return RoleMapping{BackendRoles: bp.GetBackendRolesForMapping(roleType)}
 }
 
+// GetBackendRolesForMapping returns backend roles mapped to the role of the given type, they are backend roles of
+// the role type and of role types which include it. Users of databases which reached their quota have restricted
+// variants of backend roles which include admin role, they keep permissions of other roles.
 func (bp BaseProvider) GetBackendRolesForMapping(roleType string) []string {
-	if roleType == AdminRoleType {
-		return []string{fmt.Sprintf(BackendRolePattern, AdminRoleType), fmt.Sprintf(BackendRolePattern, IsmRoleType)}
+	restrictable := bp.grantees(AdminRoleType)
+	var backendRoles []string
+	if roleType == QuotaExceededAdminRoleType {
+		for _, grantee := range restrictable {
+			backendRoles = append(backendRoles, fmt.Sprintf(BackendRolePattern, grantee)+quotaExceededSuffix)
+		}
+		return backendRoles
+	}
+	for _, grantee := range bp.grantees(roleType) {
+		backendRoles = append(backendRoles, bp.GetBackendRoles(grantee)...)
+		if roleType != AdminRoleType && slices.Contains(restrictable, grantee) {
+			backendRoles = append(backendRoles, fmt.Sprintf(BackendRolePattern, grantee)+quotaExceededSuffix)
+		}
+	}
+	if len(backendRoles) == 0 {
+		return bp.GetBackendRoles(roleType)
 	}
-	return []string{fmt.Sprintf(BackendRolePattern, roleType)}
+	return backendRoles
 }
 
 func (bp BaseProvider) GetRoleMapping(roleName string) (*RoleMapping, error) {
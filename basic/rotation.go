@@ -0,0 +1,220 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/gorilla/mux"
+)
+
+// expiresAtAttributeName marks users which are replaced by shadow users during credentials rotation,
+// such users are removed after the specified time and are not considered as users of the database anymore.
+const expiresAtAttributeName = "expires_at"
+
+type RotateCredentialsRequest struct {
+	// GracePeriodSeconds keeps previous credentials valid for the specified time, new credentials are given to shadow users
+	GracePeriodSeconds int `json:"gracePeriodSeconds,omitempty"`
+}
+
+type RotatedCredentials struct {
+	ConnectionProperties []common.ConnectionProperties `json:"connectionProperties"`
+	// ExpiresAt is the time when previous credentials are removed, it is empty if there is no grace period
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+func (bp BaseProvider) RotateCredentialsHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.PrepareContext(r)
+		prefix := mux.Vars(r)["prefix"]
+		logger.InfoContext(ctx, fmt.Sprintf("Request to rotate credentials of '%s' database is received", prefix))
+		defer r.Body.Close()
+		var request RotateCredentialsRequest
+		var violations []Violation
+		// the request body is optional
+		body, err := io.ReadAll(r.Body)
+		if err == nil && len(bytes.TrimSpace(body)) > 0 {
+			violations, err = decodeRequest(bytes.NewReader(body), &request)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to decode request in rotate credentials handler", slog.Any("error", err))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
+			return
+		}
+		if request.GracePeriodSeconds < 0 {
+			violations = append(violations, Violation{Field: "gracePeriodSeconds", Message: "must not be negative"})
+		}
+		if len(violations) > 0 {
+			err = newValidationError(violations)
+			logger.ErrorContext(ctx, "Rotate credentials request is not valid", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+
+		response, err := bp.rotateCredentials(prefix, time.Duration(request.GracePeriodSeconds)*time.Second, ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to rotate credentials of '%s' database", prefix), slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		responseBody, err := json.Marshal(response)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}
+}
+
+// rotateCredentials generates new passwords for all users of the database and applies them with one batch.
+// If grace period is specified, existing users keep their passwords until it expires and new passwords are given
+// to shadow users with the same attributes and backend roles.
+func (bp BaseProvider) rotateCredentials(prefix string, gracePeriod time.Duration, ctx context.Context) (*RotatedCredentials, error) {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	users, err := bp.getUsers()
+	if err != nil {
+		return nil, err
+	}
+	var usernames []string
+	for name, user := range users {
+		if user.Attributes[resourcePrefixAttributeName] == prefix && user.Attributes[expiresAtAttributeName] == "" {
+			usernames = append(usernames, name)
+		}
+	}
+	if len(usernames) == 0 {
+		return nil, common.NewNotFoundError(common.NotFoundCode, fmt.Errorf("there are no users for '%s' database", prefix))
+	}
+	sort.Strings(usernames)
+
+	response := &RotatedCredentials{}
+	if gracePeriod > 0 {
+		response.ExpiresAt = time.Now().Add(gracePeriod).UTC().Format(time.RFC3339)
+	}
+	var changes []Change
+	for _, username := range usernames {
+		user := users[username]
+		password, err := bp.passwordGenerator.Generate()
+		if err != nil {
+			return nil, err
+		}
+		rotatedUsername := username
+		if gracePeriod > 0 {
+			rotatedUsername = prefix + resourcePrefixDelimiter + common.GenerateUUID()
+			attributes := make(map[string]string, len(user.Attributes)+1)
+			for key, value := range user.Attributes {
+				attributes[key] = value
+			}
+			attributes[expiresAtAttributeName] = response.ExpiresAt
+			changes = append(changes, Change{Operation: "add", Path: fmt.Sprintf("/%s/attributes", username), Value: attributes})
+		}
+		changes = append(changes, Change{
+			Operation: "add",
+			Path:      fmt.Sprintf("/%s", rotatedUsername),
+			Value: Content{
+				Attributes:   user.Attributes,
+				BackendRoles: user.Roles,
+				Password:     password,
+			},
+		})
+		response.ConnectionProperties = append(response.ConnectionProperties,
+			bp.GetExtendedConnectionProperties("", rotatedUsername, password, prefix, bp.roleTypeByBackendRoles(user.Roles)))
+	}
+	if err = bp.patchUsers(changes, ctx); err != nil {
+		return nil, fmt.Errorf("failed to rotate credentials of '%s' database: %w", prefix, err)
+	}
+	if gracePeriod > 0 {
+		if err = bp.syncDatabaseRoleUsers(prefix, ctx); err != nil {
+			return nil, fmt.Errorf("failed to grant additional permissions of '%s' database to shadow users: %w", prefix, err)
+		}
+		logger.InfoContext(ctx, fmt.Sprintf("Credentials of '%s' database are rotated, previous ones are valid until %s",
+			prefix, response.ExpiresAt))
+	} else {
+		logger.InfoContext(ctx, fmt.Sprintf("Credentials of '%s' database are rotated", prefix))
+	}
+	return response, nil
+}
+
+// StartExpiredUsersSweeper removes expired users on start and then with the given interval until shutdown.
+func (bp *BaseProvider) StartExpiredUsersSweeper(interval time.Duration) {
+	bp.runPeriodically(interval, func() {
+		bp.RemoveExpiredUsers(context.Background())
+	})
+}
+
+// RemoveExpiredUsers removes users whose grace period after credentials rotation is over.
+func (bp BaseProvider) RemoveExpiredUsers(ctx context.Context) {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	users, err := bp.getUsers()
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to receive users to remove expired ones", slog.Any("error", err))
+		return
+	}
+	now := time.Now()
+	prefixes := make(map[string]struct{})
+	for name, user := range users {
+		if !isExpired(user, now) {
+			continue
+		}
+		if err = bp.deleteUser(name, ctx); err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to remove expired '%s' user", name), slog.Any("error", err))
+			continue
+		}
+		logger.InfoContext(ctx, fmt.Sprintf("Expired '%s' user is removed", name))
+		prefixes[user.Attributes[resourcePrefixAttributeName]] = struct{}{}
+	}
+	for prefix := range prefixes {
+		if err = bp.syncDatabaseRoleUsers(prefix, ctx); err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to revoke additional permissions of '%s' database from expired users",
+				prefix), slog.Any("error", err))
+		}
+	}
+}
+
+func isExpired(user User, now time.Time) bool {
+	value := user.Attributes[expiresAtAttributeName]
+	if value == "" {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	return err == nil && !expiresAt.After(now)
+}
+
+// roleTypeByBackendRoles returns role type of the user, users of v1 version have only admin role.
+// Backend roles restricted by quota are considered as original ones.
+func (bp BaseProvider) roleTypeByBackendRoles(backendRoles []string) string {
+	backendRoles = bp.quotaBackendRoles(backendRoles, false)
+	for _, roleType := range bp.GetSupportedRoleTypes() {
+		for _, backendRole := range bp.GetBackendRoles(roleType) {
+			if slices.Contains(backendRoles, backendRole) {
+				return roleType
+			}
+		}
+	}
+	return AdminRoleType
+}
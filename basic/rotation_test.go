@@ -0,0 +1,134 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateCredentials(t *testing.T) {
+	provider, client := newTestProvider()
+	response, err := provider.rotateCredentials("stubprefix", 0, ctx)
+	require.NoError(t, err)
+	assert.Empty(t, response.ExpiresAt)
+	require.Len(t, response.ConnectionProperties, 4)
+	roles := make(map[string]string)
+	for _, properties := range response.ConnectionProperties {
+		assert.NotEmpty(t, properties.Password)
+		assert.Equal(t, "stubprefix", properties.ResourcePrefix)
+		roles[properties.Role] = properties.Username
+	}
+	assert.Equal(t, "stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1", roles[AdminRoleType])
+	assert.Equal(t, "stubprefix_9f1e7c3a2b4d4f6e8a0c5b7d9e1f3a5c", roles[ReadOnlyRoleType])
+	assert.Equal(t, "stubprefix_b2d4f6a8c0e24a6c8e0a2c4e6a8c0e2a", roles[DmlRoleType])
+	assert.Equal(t, "stubprefix_c3e5a7c9e1f34b5d7f9b1d3f5b7d9f1b", roles[IsmRoleType])
+
+	require.Len(t, client.patches, 1)
+	require.Len(t, client.patches[0], 4)
+	for _, change := range client.patches[0] {
+		assert.Equal(t, "add", change.Operation)
+		assert.Equal(t, "/"+roles[provider.roleTypeByBackendRoles(backendRolesOf(change))], change.Path)
+	}
+}
+
+func TestRotateCredentialsWithGracePeriod(t *testing.T) {
+	provider, client := newTestProvider()
+	response, err := provider.rotateCredentials("stubprefix", time.Hour, ctx)
+	require.NoError(t, err)
+	expiresAt, err := time.Parse(time.RFC3339, response.ExpiresAt)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+	require.Len(t, response.ConnectionProperties, 4)
+	for _, properties := range response.ConnectionProperties {
+		assert.True(t, strings.HasPrefix(properties.Username, "stubprefix_"))
+		assert.NotContains(t, []string{
+			"stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1",
+			"stubprefix_9f1e7c3a2b4d4f6e8a0c5b7d9e1f3a5c",
+			"stubprefix_b2d4f6a8c0e24a6c8e0a2c4e6a8c0e2a",
+			"stubprefix_c3e5a7c9e1f34b5d7f9b1d3f5b7d9f1b",
+		}, properties.Username)
+	}
+
+	require.Len(t, client.patches, 1)
+	require.Len(t, client.patches[0], 8)
+	expiring := 0
+	for _, change := range client.patches[0] {
+		if strings.HasSuffix(change.Path, "/attributes") {
+			expiring++
+			attributes := change.Value.(map[string]interface{})
+			assert.Equal(t, response.ExpiresAt, attributes[expiresAtAttributeName])
+			assert.Equal(t, "stubprefix", attributes[resourcePrefixAttributeName])
+		}
+	}
+	assert.Equal(t, 4, expiring)
+}
+
+func TestRotateCredentialsOfUnknownDatabase(t *testing.T) {
+	provider, client := newTestProvider()
+	_, err := provider.rotateCredentials("unknown", 0, ctx)
+	var apiErr *common.Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
+	assert.Empty(t, client.patches)
+}
+
+func TestRotateCredentialsHandler(t *testing.T) {
+	provider, _ := newTestProvider()
+	request := httptest.NewRequest(http.MethodPost, "/databases/stubprefix/rotate-credentials", nil)
+	request = mux.SetURLVars(request, map[string]string{"prefix": "stubprefix"})
+	recorder := httptest.NewRecorder()
+	provider.RotateCredentialsHandler()(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response RotatedCredentials
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Len(t, response.ConnectionProperties, 4)
+
+	request = httptest.NewRequest(http.MethodPost, "/databases/stubprefix/rotate-credentials",
+		strings.NewReader(`{"gracePeriodSeconds":-1}`))
+	request = mux.SetURLVars(request, map[string]string{"prefix": "stubprefix"})
+	recorder = httptest.NewRecorder()
+	provider.RotateCredentialsHandler()(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), common.ValidationErrorCode)
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Now()
+	assert.False(t, isExpired(User{}, now))
+	assert.False(t, isExpired(User{Attributes: map[string]string{expiresAtAttributeName: "invalid"}}, now))
+	assert.False(t, isExpired(User{Attributes: map[string]string{
+		expiresAtAttributeName: now.Add(time.Minute).Format(time.RFC3339)}}, now))
+	assert.True(t, isExpired(User{Attributes: map[string]string{
+		expiresAtAttributeName: now.Add(-time.Minute).Format(time.RFC3339)}}, now))
+}
+
+func backendRolesOf(change Change) []string {
+	var roles []string
+	for _, role := range change.Value.(map[string]interface{})["backend_roles"].([]interface{}) {
+		roles = append(roles, role.(string))
+	}
+	return roles
+}
@@ -0,0 +1,451 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/gorilla/mux"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+// TombstonesIndex keeps resources of soft deleted databases until their retention is over
+const TombstonesIndex = "dbaas_opensearch_tombstones"
+
+// Tombstone replaces metadata documents of soft deleted database. Indices of the database are closed and its users
+// are disabled until the tombstone expires, after that all resources are deleted permanently.
+type Tombstone struct {
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deletedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// Metadata contains removed metadata documents by their identifiers
+	Metadata  map[string]map[string]interface{} `json:"metadata,omitempty"`
+	Resources []dao.DbResource                  `json:"resources"`
+	// UserRoles contains backend roles of disabled users to restore them on undelete
+	UserRoles map[string][]string `json:"userRoles,omitempty"`
+	// RoleUsers contains users of removed role mappings of database roles to restore them on undelete
+	RoleUsers map[string][]string `json:"roleUsers,omitempty"`
+}
+
+type tombstoneResponse struct {
+	Found  bool      `json:"found"`
+	Source Tombstone `json:"_source"`
+}
+
+type tombstonesSearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source Tombstone `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// ConfigureSoftDelete enables soft delete of resources by bulk drop, resources are deleted permanently after retention.
+func (bp *BaseProvider) ConfigureSoftDelete(retention time.Duration) {
+	bp.softDeleteRetention = retention
+}
+
+func (bp BaseProvider) EnsureTombstonesIndex() {
+	bp.ensureIndex(TombstonesIndex)
+}
+
+// StartTombstonesReaper deletes resources of expired tombstones on start and then with the given interval until shutdown.
+func (bp *BaseProvider) StartTombstonesReaper(interval time.Duration) {
+	bp.runPeriodically(interval, func() {
+		bp.ReapTombstones(context.Background())
+	})
+}
+
+func (bp BaseProvider) UndeleteDatabaseHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.PrepareContext(r)
+		name := mux.Vars(r)["name"]
+		logger.InfoContext(ctx, fmt.Sprintf("Request to undelete '%s' database is received", name))
+		restoredResources, err := bp.undeleteDatabase(name, ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to undelete '%s' database", name), slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		responseBody, err := json.Marshal(restoredResources)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to serialize resources list", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}
+}
+
+// softDeleteResources is used by bulk drop instead of deleteResources when soft delete is enabled. Resources
+// of each resource prefix are kept in a separate tombstone named by the prefix, other resources of the request
+// are kept in one tombstone named by their metadata document.
+func (bp BaseProvider) softDeleteResources(resources []dao.DbResource, ctx context.Context) []dao.DbResource {
+	var deletedResources []dao.DbResource
+	var explicitResources []dao.DbResource
+	tombstoneName := ""
+	for _, resource := range resources {
+		if resource.Kind == common.ResourcePrefixKind {
+			prefixResources, err := bp.processResourcePrefixKind([]dao.DbResource{resource}, ctx)
+			if err != nil {
+				logger.ErrorContext(ctx, fmt.Sprintf("Failed to receive resources of '%s' resource prefix", resource.Name), slog.Any("error", err))
+				deletedResources = append(deletedResources, *getResourceDeletionFailedStatus(resource, err))
+				continue
+			}
+			deletedResources = append(deletedResources, bp.softDeleteDatabase(resource.Name, prefixResources, ctx)...)
+			continue
+		}
+		explicitResources = append(explicitResources, resource)
+	}
+	for _, resource := range explicitResources {
+		if resource.Kind == common.MetadataKind {
+			tombstoneName = resource.Name
+			break
+		}
+	}
+	if tombstoneName == "" && len(explicitResources) > 0 {
+		tombstoneName = explicitResources[0].Name
+	}
+	if len(explicitResources) > 0 {
+		deletedResources = append(deletedResources, bp.softDeleteDatabase(tombstoneName, explicitResources, ctx)...)
+	}
+	return deletedResources
+}
+
+// softDeleteDatabase saves the tombstone with the state of resources before they are changed, then closes indices,
+// removes backend roles of users and role mappings of database roles and deletes metadata documents. Templates
+// and aliases are kept until the tombstone expires.
+func (bp BaseProvider) softDeleteDatabase(name string, resources []dao.DbResource, ctx context.Context) []dao.DbResource {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	tombstone, err := bp.getTombstone(name, ctx)
+	if err != nil {
+		return getResourcesDeletionFailedStatus(resources, err)
+	}
+	if tombstone == nil {
+		tombstone = &Tombstone{Name: name}
+	}
+	if tombstone.Metadata == nil {
+		tombstone.Metadata = make(map[string]map[string]interface{})
+	}
+	if tombstone.UserRoles == nil {
+		tombstone.UserRoles = make(map[string][]string)
+	}
+	if tombstone.RoleUsers == nil {
+		tombstone.RoleUsers = make(map[string][]string)
+	}
+	tombstone.DeletedAt = time.Now().UTC()
+	tombstone.ExpiresAt = tombstone.DeletedAt.Add(bp.softDeleteRetention)
+
+	var deletedResources []dao.DbResource
+	var existingResources []dao.DbResource
+	for _, kind := range resourceKindsDeletionOrder {
+		for _, resource := range resources {
+			if resource.Kind != kind {
+				continue
+			}
+			exists, err := bp.captureResource(resource, tombstone, ctx)
+			if err != nil {
+				logger.ErrorContext(ctx, fmt.Sprintf("Failed to receive '%s' %s", resource.Name, resource.Kind), slog.Any("error", err))
+				deletedResources = append(deletedResources, *getResourceDeletionFailedStatus(resource, err))
+				continue
+			}
+			if !exists {
+				logger.InfoContext(ctx, fmt.Sprintf("'%s' %s does not exist, skip deletion", resource.Name, resource.Kind))
+				deletedResources = append(deletedResources, *getResourceDeletionSuccessStatus(resource))
+				continue
+			}
+			existingResources = append(existingResources, resource)
+		}
+	}
+	if err = bp.saveTombstone(tombstone, ctx); err != nil {
+		return append(deletedResources, getResourcesDeletionFailedStatus(existingResources, err)...)
+	}
+
+	for _, resource := range existingResources {
+		if err = bp.softDeleteResource(resource, ctx); err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to soft delete '%s' %s", resource.Name, resource.Kind), slog.Any("error", err))
+			deletedResources = append(deletedResources, *getResourceDeletionFailedStatus(resource, err))
+			continue
+		}
+		deletedResources = append(deletedResources, *getResourceDeletionSuccessStatus(resource))
+	}
+	logger.InfoContext(ctx, fmt.Sprintf("'%s' database is soft deleted, it can be restored until %s", name,
+		tombstone.ExpiresAt.Format(time.RFC3339)))
+	return deletedResources
+}
+
+// captureResource records the resource in the tombstone and returns whether it exists.
+func (bp BaseProvider) captureResource(resource dao.DbResource, tombstone *Tombstone, ctx context.Context) (bool, error) {
+	switch resource.Kind {
+	case common.MetadataKind:
+		metadata, err := bp.GetMetadata(resource.Name, ctx)
+		if err != nil || metadata == nil {
+			return false, err
+		}
+		tombstone.Metadata[resource.Name] = metadata
+		return true, nil
+	case common.UserKind:
+		user, err := bp.GetUser(resource.Name)
+		if err != nil || user == nil {
+			return false, err
+		}
+		// roles of the user which is already disabled by previous attempt are not overwritten
+		if _, ok := tombstone.UserRoles[resource.Name]; !ok {
+			tombstone.UserRoles[resource.Name] = user.Roles
+		}
+	case common.IndexKind:
+		database, err := bp.getDatabase(resource.Name)
+		if err != nil || database == nil {
+			return false, err
+		}
+	case common.RoleKind:
+		role, err := bp.GetRole(resource.Name)
+		if err != nil || role == nil {
+			return false, err
+		}
+		if _, ok := databaseRolePrefix(resource.Name, *role); !ok {
+			return false, fmt.Errorf("'%s' role is not created for a database, it cannot be deleted", resource.Name)
+		}
+		// users of the role mapping which is already removed by previous attempt are not overwritten
+		if _, ok := tombstone.RoleUsers[resource.Name]; !ok {
+			mapping, err := bp.GetRoleMapping(resource.Name)
+			if err != nil {
+				return false, err
+			}
+			users := make([]string, 0)
+			if mapping != nil {
+				users = mapping.Users
+			}
+			tombstone.RoleUsers[resource.Name] = users
+		}
+	}
+	if !slices.Contains(tombstone.Resources, resource) {
+		tombstone.Resources = append(tombstone.Resources, resource)
+	}
+	return true, nil
+}
+
+func (bp BaseProvider) softDeleteResource(resource dao.DbResource, ctx context.Context) error {
+	switch resource.Kind {
+	case common.IndexKind:
+		closeRequest := opensearchapi.IndicesCloseRequest{Index: []string{resource.Name}}
+		return bp.doIndicesRequest(closeRequest, fmt.Sprintf("close '%s' index", resource.Name))
+	case common.UserKind:
+		return bp.patchUsers([]Change{
+			{Operation: "add", Path: fmt.Sprintf("/%s/backend_roles", resource.Name), Value: []string{}},
+		}, ctx)
+	case common.MetadataKind:
+		return bp.deleteMetadata(resource.Name, ctx)
+	case common.RoleKind:
+		return bp.deleteRoleMapping(resource.Name)
+	}
+	return nil
+}
+
+// undeleteDatabase opens indices, restores backend roles of users, role mappings and metadata documents of the tombstone
+// which is not expired yet, then the tombstone is removed.
+func (bp BaseProvider) undeleteDatabase(name string, ctx context.Context) ([]dao.DbResource, error) {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	tombstone, err := bp.getTombstone(name, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tombstone == nil {
+		return nil, common.NewNotFoundError(common.NotFoundCode, fmt.Errorf("there is no soft deleted '%s' database", name))
+	}
+	if !tombstone.ExpiresAt.After(time.Now()) {
+		return nil, common.NewNotFoundError(common.NotFoundCode,
+			fmt.Errorf("retention of soft deleted '%s' database is over at %s", name, tombstone.ExpiresAt.Format(time.RFC3339)))
+	}
+
+	restoredResources := make([]dao.DbResource, 0, len(tombstone.Resources)+len(tombstone.Metadata))
+	var changes []Change
+	for _, resource := range tombstone.Resources {
+		switch resource.Kind {
+		case common.IndexKind:
+			openRequest := opensearchapi.IndicesOpenRequest{Index: []string{resource.Name}}
+			if err = bp.doIndicesRequest(openRequest, fmt.Sprintf("open '%s' index", resource.Name)); err != nil {
+				return nil, err
+			}
+		case common.UserKind:
+			changes = append(changes, Change{
+				Operation: "add",
+				Path:      fmt.Sprintf("/%s/backend_roles", resource.Name),
+				Value:     tombstone.UserRoles[resource.Name],
+			})
+		case common.RoleKind:
+			if err = bp.createRoleMapping(resource.Name, RoleMapping{Users: tombstone.RoleUsers[resource.Name]}); err != nil {
+				return nil, err
+			}
+		}
+		restoredResources = append(restoredResources, dao.DbResource{Kind: resource.Kind, Name: resource.Name})
+	}
+	if err = bp.patchUsers(changes, ctx); err != nil {
+		return nil, fmt.Errorf("failed to restore roles of users: %w", err)
+	}
+	for identifier, metadata := range tombstone.Metadata {
+		if _, err = bp.CreateMetadata(identifier, metadata, ctx); err != nil {
+			return nil, err
+		}
+		restoredResources = append(restoredResources, dao.DbResource{Kind: common.MetadataKind, Name: identifier})
+	}
+	if err = bp.deleteTombstone(name, ctx); err != nil {
+		return nil, err
+	}
+	logger.InfoContext(ctx, fmt.Sprintf("Soft deleted '%s' database is restored", name))
+	return restoredResources, nil
+}
+
+// ReapTombstones permanently deletes resources of expired tombstones. Tombstone is kept with resources
+// which are failed to delete, so they are retried next time.
+func (bp BaseProvider) ReapTombstones(ctx context.Context) {
+	size := metadataSearchSize
+	searchRequest := opensearchapi.SearchRequest{
+		Index: []string{TombstonesIndex},
+		Size:  &size,
+	}
+	var response tombstonesSearchResponse
+	if err := common.DoRequest(searchRequest, bp.opensearch.Client, &response, ctx); err != nil {
+		logger.ErrorContext(ctx, "Failed to receive tombstones of soft deleted databases", slog.Any("error", err))
+		return
+	}
+	now := time.Now()
+	for _, hit := range response.Hits.Hits {
+		tombstone := hit.Source
+		if tombstone.ExpiresAt.After(now) {
+			continue
+		}
+		logger.InfoContext(ctx, fmt.Sprintf("Retention of soft deleted '%s' database is over, deleting its resources", tombstone.Name))
+		var failedResources []dao.DbResource
+		for _, kind := range resourceKindsDeletionOrder {
+			for _, resource := range tombstone.Resources {
+				if resource.Kind != kind {
+					continue
+				}
+				if deleted := bp.deleteResource(resource, ctx); deleted.Status == DeletionFailedStatus {
+					failedResources = append(failedResources, resource)
+				}
+			}
+		}
+		var err error
+		if len(failedResources) > 0 {
+			tombstone.Resources = failedResources
+			err = bp.saveTombstone(&tombstone, ctx)
+		} else {
+			err = bp.deleteTombstone(tombstone.Name, ctx)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to update tombstone of '%s' database", tombstone.Name), slog.Any("error", err))
+		}
+	}
+}
+
+func (bp BaseProvider) getTombstone(name string, ctx context.Context) (*Tombstone, error) {
+	getRequest := opensearchapi.GetRequest{
+		Index:      TombstonesIndex,
+		DocumentID: name,
+	}
+	response, err := getRequest.Do(context.Background(), bp.opensearch.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive tombstone of '%s' database: %w", name, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		logger.DebugContext(ctx, fmt.Sprintf("Tombstone of '%s' database is not found", name))
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to receive tombstone of '%s' database: %s", name, response.String())
+	}
+	var tombstone tombstoneResponse
+	if err = common.ProcessBody(response.Body, &tombstone); err != nil {
+		return nil, fmt.Errorf("failed to parse tombstone of '%s' database: %w", name, err)
+	}
+	if !tombstone.Found {
+		return nil, nil
+	}
+	return &tombstone.Source, nil
+}
+
+func (bp BaseProvider) saveTombstone(tombstone *Tombstone, ctx context.Context) error {
+	body, err := json.Marshal(tombstone)
+	if err != nil {
+		return err
+	}
+	indexRequest := opensearchapi.IndexRequest{
+		Index:      TombstonesIndex,
+		DocumentID: tombstone.Name,
+		Body:       strings.NewReader(string(body)),
+	}
+	response, err := indexRequest.Do(context.Background(), bp.opensearch.Client)
+	if err != nil {
+		return fmt.Errorf("failed to save tombstone of '%s' database: %w", tombstone.Name, err)
+	}
+	defer response.Body.Close()
+	if response.IsError() {
+		return fmt.Errorf("failed to save tombstone of '%s' database: %s", tombstone.Name, response.String())
+	}
+	logger.DebugContext(ctx, fmt.Sprintf("Tombstone of '%s' database is saved", tombstone.Name))
+	return nil
+}
+
+func (bp BaseProvider) deleteTombstone(name string, ctx context.Context) error {
+	deleteRequest := opensearchapi.DeleteRequest{
+		Index:      TombstonesIndex,
+		DocumentID: name,
+	}
+	response, err := deleteRequest.Do(context.Background(), bp.opensearch.Client)
+	if err != nil {
+		return fmt.Errorf("failed to delete tombstone of '%s' database: %w", name, err)
+	}
+	defer response.Body.Close()
+	if response.IsError() && response.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete tombstone of '%s' database: %s", name, response.String())
+	}
+	logger.DebugContext(ctx, fmt.Sprintf("Tombstone of '%s' database is deleted", name))
+	return nil
+}
+
+func (bp BaseProvider) doIndicesRequest(request opensearchapi.Request, action string) error {
+	response, err := request.Do(context.Background(), bp.opensearch.Client)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer response.Body.Close()
+	if response.IsError() {
+		return fmt.Errorf("failed to %s: %s", action, response.String())
+	}
+	return nil
+}
+
+func getResourcesDeletionFailedStatus(resources []dao.DbResource, err error) []dao.DbResource {
+	result := make([]dao.DbResource, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, *getResourceDeletionFailedStatus(resource, err))
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftDeleteResourcesByPrefix(t *testing.T) {
+	provider, client := newTestProvider()
+	provider.softDeleteRetention = 24 * time.Hour
+	deletedResources := provider.softDeleteResources([]dao.DbResource{{Kind: common.ResourcePrefixKind, Name: "stubprefix"}}, ctx)
+	assert.Empty(t, getResourcesWithFailedStatus(deletedResources))
+	assert.Contains(t, deletedResources, dao.DbResource{Kind: common.IndexKind, Name: "stubprefix_orders", Status: DeletedStatus})
+
+	require.Len(t, client.tombstones, 1)
+	tombstone := client.tombstones[0]
+	assert.Equal(t, "stubprefix", tombstone.Name)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), tombstone.ExpiresAt, time.Minute)
+	assert.Contains(t, tombstone.Metadata, "stubprefix")
+	assert.Contains(t, tombstone.Resources, dao.DbResource{Kind: common.IndexKind, Name: "stubprefix_customers"})
+	assert.Contains(t, tombstone.Resources, dao.DbResource{Kind: common.AliasKind, Name: "stubprefix_alias"})
+	assert.Len(t, tombstone.UserRoles, 4)
+	assert.Contains(t, tombstone.Resources, dao.DbResource{Kind: common.RoleKind, Name: "dbaas_db_stubprefix_admin"})
+	assert.Contains(t, tombstone.RoleUsers, "dbaas_db_stubprefix_admin")
+
+	assert.Contains(t, client.requests, "POST /stubprefix_orders/_close")
+	deleteRequests := []string{
+		"DELETE /_plugins/_security/api/rolesmapping/dbaas_db_stubprefix_admin",
+		"DELETE /dbaas_opensearch_metadata/_doc/stubprefix",
+	}
+	for _, request := range deleteRequests {
+		assert.Contains(t, client.requests, request)
+	}
+	for _, request := range client.requests {
+		if strings.HasPrefix(request, http.MethodDelete) {
+			assert.Contains(t, deleteRequests, request)
+		}
+	}
+	require.Len(t, client.patches, 4)
+	for _, changes := range client.patches {
+		require.Len(t, changes, 1)
+		assert.True(t, strings.HasSuffix(changes[0].Path, "/backend_roles"))
+		assert.Empty(t, changes[0].Value)
+	}
+}
+
+func TestUndeleteDatabase(t *testing.T) {
+	provider, client := newTestProvider()
+	provider.softDeleteRetention = 24 * time.Hour
+	restoredResources, err := provider.undeleteDatabase("deletedprefix", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []dao.DbResource{
+		{Kind: common.UserKind, Name: "deletedprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1"},
+		{Kind: common.IndexKind, Name: "deletedprefix_orders"},
+		{Kind: common.AliasKind, Name: "deletedprefix_alias"},
+		{Kind: common.MetadataKind, Name: "deletedprefix"},
+	}, restoredResources)
+	assert.Contains(t, client.requests, "POST /deletedprefix_orders/_open")
+	assert.Contains(t, client.requests, "PUT /dbaas_opensearch_metadata/_doc/deletedprefix")
+	assert.Contains(t, client.requests, "DELETE /dbaas_opensearch_tombstones/_doc/deletedprefix")
+	require.Len(t, client.patches, 1)
+	assert.Equal(t, "/deletedprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1/backend_roles", client.patches[0][0].Path)
+	assert.Equal(t, []interface{}{"deletedprefix_admin"}, client.patches[0][0].Value)
+}
+
+func TestUndeleteDatabaseHandler(t *testing.T) {
+	provider, _ := newTestProvider()
+	provider.softDeleteRetention = 24 * time.Hour
+	for name, status := range map[string]int{
+		"deletedprefix":     http.StatusOK,
+		"expiredprefix":     http.StatusNotFound,
+		"unknown":           http.StatusNotFound,
+		"unavailableprefix": http.StatusInternalServerError,
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/databases/"+name+"/undelete", nil)
+		request = mux.SetURLVars(request, map[string]string{"name": name})
+		recorder := httptest.NewRecorder()
+		provider.UndeleteDatabaseHandler()(recorder, request)
+		assert.Equal(t, status, recorder.Code, name)
+	}
+}
+
+func TestReapTombstones(t *testing.T) {
+	provider, client := newTestProvider()
+	provider.softDeleteRetention = 24 * time.Hour
+	provider.ReapTombstones(ctx)
+	assert.Contains(t, client.requests, "DELETE /_plugins/_security/api/internalusers/expiredprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1")
+	assert.Contains(t, client.requests, "DELETE /expiredprefix_orders")
+	assert.Contains(t, client.requests, "DELETE /dbaas_opensearch_tombstones/_doc/expiredprefix")
+	assert.NotContains(t, client.requests, "DELETE /deletedprefix_orders")
+	assert.NotContains(t, client.requests, "DELETE /dbaas_opensearch_tombstones/_doc/deletedprefix")
+}
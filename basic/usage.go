@@ -0,0 +1,212 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/gorilla/mux"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+// DatabaseUsage is the sum of statistics of indices which belong to the database.
+type DatabaseUsage struct {
+	Classifier map[string]interface{} `json:"classifier,omitempty"`
+	Indices    int64                  `json:"indices"`
+	Primaries  UsageStats             `json:"primaries"`
+	Total      UsageStats             `json:"total"`
+}
+
+type UsageStats struct {
+	DocsCount   int64 `json:"docsCount"`
+	DocsDeleted int64 `json:"docsDeleted"`
+	StoreBytes  int64 `json:"storeBytes"`
+	Segments    int64 `json:"segments"`
+	QueryTotal  int64 `json:"queryTotal"`
+	IndexTotal  int64 `json:"indexTotal"`
+}
+
+// indexStats is a part of `_stats` response for primary shards or all shards of the index
+type indexStats struct {
+	Docs struct {
+		Count   int64 `json:"count"`
+		Deleted int64 `json:"deleted"`
+	} `json:"docs"`
+	Store struct {
+		SizeInBytes int64 `json:"size_in_bytes"`
+	} `json:"store"`
+	Segments struct {
+		Count int64 `json:"count"`
+	} `json:"segments"`
+	Search struct {
+		QueryTotal int64 `json:"query_total"`
+	} `json:"search"`
+	Indexing struct {
+		IndexTotal int64 `json:"index_total"`
+	} `json:"indexing"`
+}
+
+type indicesStatsResponse struct {
+	Indices map[string]struct {
+		Primaries indexStats `json:"primaries"`
+		Total     indexStats `json:"total"`
+	} `json:"indices"`
+}
+
+func (s *UsageStats) add(stats indexStats) {
+	s.DocsCount += stats.Docs.Count
+	s.DocsDeleted += stats.Docs.Deleted
+	s.StoreBytes += stats.Store.SizeInBytes
+	s.Segments += stats.Segments.Count
+	s.QueryTotal += stats.Search.QueryTotal
+	s.IndexTotal += stats.Indexing.IndexTotal
+}
+
+func (bp BaseProvider) GetDatabaseUsageHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.PrepareContext(r)
+		prefix := mux.Vars(r)["prefix"]
+		logger.InfoContext(ctx, fmt.Sprintf("Request to get usage of '%s' database is received", prefix))
+		usage, err := bp.getDatabasesUsage([]string{prefix}, ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Failed to get usage of '%s' database", prefix), slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		bp.writeUsage(w, usage[prefix], ctx)
+	}
+}
+
+func (bp BaseProvider) GetDatabasesUsageHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.PrepareContext(r)
+		logger.InfoContext(ctx, "Request to get usage of databases is received")
+		defer r.Body.Close()
+		var prefixes []string
+		// the request body is optional, all databases are reported without it
+		body, err := io.ReadAll(r.Body)
+		if err == nil && len(body) > 0 {
+			err = json.Unmarshal(body, &prefixes)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to decode request in databases usage handler", slog.Any("error", err))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
+			return
+		}
+		usage, err := bp.getDatabasesUsage(prefixes, ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to get usage of databases", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
+		bp.writeUsage(w, usage, ctx)
+	}
+}
+
+func (bp BaseProvider) writeUsage(w http.ResponseWriter, usage interface{}, ctx context.Context) {
+	responseBody, err := json.Marshal(usage)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to serialize usage of databases", slog.Any("error", err))
+		common.WriteError(w, err, ctx)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(responseBody)
+}
+
+// getDatabasesUsage aggregates `_stats` of indices by databases with the given prefixes or by all databases which have
+// metadata documents if no prefixes are given. Indices are attributed to databases the same way as on bulk drop.
+func (bp BaseProvider) getDatabasesUsage(prefixes []string, ctx context.Context) (map[string]DatabaseUsage, error) {
+	metadata, err := bp.listMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := bp.getUsers()
+	if err != nil {
+		return nil, err
+	}
+	known := databasePrefixes(metadata, users)
+	if len(prefixes) == 0 {
+		for prefix := range metadata {
+			prefixes = append(prefixes, prefix)
+		}
+		sort.Strings(prefixes)
+	}
+
+	result := make(map[string]DatabaseUsage, len(prefixes))
+	patterns := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			continue
+		}
+		if _, ok := known[prefix]; !ok {
+			return nil, common.NewNotFoundError(common.NotFoundCode, fmt.Errorf("'%s' database is not found", prefix))
+		}
+		usage := DatabaseUsage{}
+		if classifier, ok := metadata[prefix]["classifier"].(map[string]interface{}); ok {
+			usage.Classifier = classifier
+		}
+		result[prefix] = usage
+		patterns = append(patterns, prefix+resourcePrefixDelimiter+"*")
+	}
+	if len(patterns) == 0 {
+		return result, nil
+	}
+
+	stats, err := bp.getIndicesStats(patterns, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for name, index := range stats.Indices {
+		owner := databaseOwner(name, "", known)
+		usage, ok := result[owner]
+		if !ok {
+			continue
+		}
+		usage.Indices++
+		usage.Primaries.add(index.Primaries)
+		usage.Total.add(index.Total)
+		result[owner] = usage
+	}
+	return result, nil
+}
+
+func (bp BaseProvider) getIndicesStats(patterns []string, ctx context.Context) (*indicesStatsResponse, error) {
+	statsRequest := opensearchapi.IndicesStatsRequest{
+		Index:  patterns,
+		Metric: []string{"docs", "store", "segments", "search", "indexing"},
+		Level:  "indices",
+	}
+	response, err := statsRequest.Do(ctx, bp.opensearch.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive statistics of indices: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to receive statistics of indices: %s", response.String())
+	}
+	var stats indicesStatsResponse
+	if err = common.ProcessBody(response.Body, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
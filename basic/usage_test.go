@@ -0,0 +1,72 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDatabasesUsage(t *testing.T) {
+	usage, err := bp.getDatabasesUsage([]string{"stubprefix"}, ctx)
+	require.NoError(t, err)
+	require.Contains(t, usage, "stubprefix")
+	assert.Equal(t, DatabaseUsage{
+		Classifier: map[string]interface{}{"microserviceName": "stub-service", "namespace": "stub-namespace"},
+		Indices:    2,
+		Primaries:  UsageStats{DocsCount: 120, DocsDeleted: 2, StoreBytes: 107520, Segments: 4, QueryTotal: 50, IndexTotal: 140},
+		Total:      UsageStats{DocsCount: 240, DocsDeleted: 4, StoreBytes: 215040, Segments: 8, QueryTotal: 100, IndexTotal: 280},
+	}, usage["stubprefix"])
+
+	usage, err = bp.getDatabasesUsage(nil, ctx)
+	require.NoError(t, err)
+	assert.Len(t, usage, 2)
+	assert.Equal(t, int64(2), usage["testme"].Indices)
+	assert.Nil(t, usage["testme"].Classifier)
+
+	_, err = bp.getDatabasesUsage([]string{"unknown"}, ctx)
+	assertErrorStatus(t, http.StatusNotFound, err)
+}
+
+func TestDatabasesUsageHandlers(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/databases/stubprefix/usage", nil)
+	request = mux.SetURLVars(request, map[string]string{"prefix": "stubprefix"})
+	recorder := httptest.NewRecorder()
+	bp.GetDatabaseUsageHandler()(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var usage DatabaseUsage
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &usage))
+	assert.Equal(t, int64(240), usage.Total.DocsCount)
+
+	recorder = httptest.NewRecorder()
+	bp.GetDatabasesUsageHandler()(recorder, httptest.NewRequest(http.MethodPost, "/databases/usage",
+		strings.NewReader(`["stubprefix"]`)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var usages map[string]DatabaseUsage
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &usages))
+	assert.Len(t, usages, 1)
+	assert.Contains(t, usages, "stubprefix")
+
+	recorder = httptest.NewRecorder()
+	bp.GetDatabasesUsageHandler()(recorder, httptest.NewRequest(http.MethodPost, "/databases/usage", strings.NewReader(`{`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
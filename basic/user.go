@@ -70,29 +70,32 @@ func (bp BaseProvider) CreateUserHandler() func(w http.ResponseWriter, r *http.R
 		}
 		logger.InfoContext(ctx, fmt.Sprintf("Request to create user with [%s] name is received", username))
 
-		decoder := json.NewDecoder(r.Body)
 		var userCreateRequest dao.UserCreateRequest
-		err := decoder.Decode(&userCreateRequest)
+		violations, err := decodeRequest(r.Body, &userCreateRequest)
 		if err != nil {
-			logger.ErrorContext(ctx, "Failed to decode request in create database handler", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			logger.ErrorContext(ctx, "Failed to decode request in create user handler", slog.Any("error", err))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
 		defer r.Body.Close()
+		violations = append(violations, bp.validateUserCreateRequest(userCreateRequest)...)
+		if len(violations) > 0 {
+			err = newValidationError(violations)
+			logger.ErrorContext(ctx, "Create user request is not valid", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
+		}
 
 		response, err := bp.ensureUser(username, userCreateRequest, ctx)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to ensure user", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		responseBody, err := json.Marshal(response)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		w.WriteHeader(http.StatusCreated)
@@ -112,6 +115,9 @@ func (bp BaseProvider) ensureUser(username string, userCreateRequest dao.UserCre
 		return nil, err
 	}
 	if dbName != "" {
+		if err = bp.syncDatabaseRoleUsers(strings.TrimRight(dbName, "*"), ctx); err != nil {
+			return nil, fmt.Errorf("failed to grant additional permissions of '%s' database: %w", dbName, err)
+		}
 		resources = append(resources, dao.DbResource{Kind: common.MetadataKind, Name: dbName})
 		resources = append(resources, dao.DbResource{Kind: common.ResourcePrefixKind, Name: dbName})
 	}
@@ -134,7 +140,7 @@ func (bp BaseProvider) ensureUser(username string, userCreateRequest dao.UserCre
 
 // CreateUserByPrefix is used to create user for v2 DBaaS adapter version
 func (bp BaseProvider) CreateUserByPrefix(prefix string, password string, dbName string, roleType string, ctx context.Context) (string, string, []dao.DbResource, error) {
-	username := fmt.Sprintf("%s_%s", prefix, common.GenerateUUID())
+	username := prefix + resourcePrefixDelimiter + common.GenerateUUID()
 	return bp.createOrUpdateUser(username, password, dbName, roleType, ctx)
 }
 
@@ -153,6 +159,8 @@ func (bp BaseProvider) createOrUpdateUser(username string, password string, dbNa
 			logger.ErrorContext(ctx, fmt.Sprintf("Cannot generate password for user [%s]", username))
 			return username, password, resources, err
 		}
+	} else if err := bp.passwordGenerator.Validate(password); err != nil {
+		return username, password, resources, common.NewBadRequestError(common.InvalidPasswordCode, err)
 	}
 
 	user, err := bp.GetUser(username)
@@ -182,8 +190,13 @@ func (bp BaseProvider) createOrUpdateUser(username string, password string, dbNa
 func (bp BaseProvider) createUser(username string, password string, prefix string, roleType string, ctx context.Context) error {
 	body := Content{Password: password}
 	if prefix != "" {
-		body.Attributes = map[string]string{resourcePrefixAttributeName: strings.TrimRight(prefix, "*")}
-		body.BackendRoles = bp.GetBackendRoles(roleType)
+		prefix = strings.TrimRight(prefix, "*")
+		backendRoles, err := bp.databaseBackendRoles(prefix, roleType, ctx)
+		if err != nil {
+			return err
+		}
+		body.Attributes = map[string]string{resourcePrefixAttributeName: prefix}
+		body.BackendRoles = backendRoles
 	}
 	processedBody, err := json.Marshal(body)
 	if err != nil {
@@ -235,9 +248,14 @@ func (bp BaseProvider) PatchUser(username string, password string, prefix string
 		body = append(body, Change{Operation: "add", Path: "/password", Value: password})
 	}
 	if prefix != "" {
+		prefix = strings.TrimRight(prefix, "*")
+		backendRoles, err := bp.databaseBackendRoles(prefix, roleType, ctx)
+		if err != nil {
+			return err
+		}
 		body = append(body, []Change{
-			{Operation: "add", Path: "/attributes", Value: map[string]string{resourcePrefixAttributeName: strings.TrimRight(prefix, "*")}},
-			{Operation: "add", Path: "/backend_roles", Value: bp.GetBackendRoles(roleType)},
+			{Operation: "add", Path: "/attributes", Value: map[string]string{resourcePrefixAttributeName: prefix}},
+			{Operation: "add", Path: "/backend_roles", Value: backendRoles},
 		}...)
 	}
 	processedBody, err := json.Marshal(body)
@@ -307,10 +325,23 @@ func (bp BaseProvider) patchUsers(changes []Change, ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if response.StatusCode >= http.StatusBadRequest && response.StatusCode < http.StatusInternalServerError {
+		return &usersBatchRejectedError{StatusCode: response.StatusCode, Response: string(responseBody)}
+	}
 	return fmt.Errorf("creation of users batch is finished with %d code, response is %s", response.StatusCode,
 		string(responseBody))
 }
 
+// usersBatchRejectedError means that OpenSearch refused the batch because of its content, so retries do not help.
+type usersBatchRejectedError struct {
+	StatusCode int
+	Response   string
+}
+
+func (e *usersBatchRejectedError) Error() string {
+	return fmt.Sprintf("creation of users batch is rejected with %d code, response is %s", e.StatusCode, e.Response)
+}
+
 func (bp BaseProvider) GetUser(username string) (*User, error) {
 	getUserRequest := api.GetUserRequest{
 		Username: username,
@@ -338,7 +369,7 @@ func (bp BaseProvider) GetUser(username string) (*User, error) {
 	return nil, fmt.Errorf("during receiving user error occurred: %+v", response.Body)
 }
 
-func (bp BaseProvider) getUsersByPrefix(prefix string) ([]string, error) {
+func (bp BaseProvider) getUsers() (map[string]User, error) {
 	getUsersRequest := api.GetUsersRequest{}
 	response, err := getUsersRequest.Do(context.Background(), bp.opensearch.Client)
 	if err != nil {
@@ -347,21 +378,12 @@ func (bp BaseProvider) getUsersByPrefix(prefix string) ([]string, error) {
 	defer response.Body.Close()
 	if response.StatusCode == http.StatusOK {
 		var users map[string]User
-		usersByPrefix := make([]string, 0)
 		err = common.ProcessBody(response.Body, &users)
-		if err != nil {
-			return nil, err
-		}
-		for element := range users {
-			if strings.HasPrefix(element, prefix) {
-				usersByPrefix = append(usersByPrefix, element)
-			}
-		}
-		return usersByPrefix, nil
+		return users, err
 	} else if response.StatusCode == http.StatusNotFound {
 		return nil, nil
 	}
-	return nil, fmt.Errorf("during receiving users by prefix %s error occurred: %+v", prefix, response.Body)
+	return nil, fmt.Errorf("during receiving users error occurred: %+v", response.Body)
 }
 
 func (bp BaseProvider) deleteUser(username string, ctx context.Context) error {
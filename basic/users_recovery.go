@@ -17,12 +17,16 @@ package basic
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/dbaas-opensearch-adapter/metrics"
 )
 
 const (
@@ -46,65 +50,288 @@ func (bp *BaseProvider) RecoverUsersHandler() func(w http.ResponseWriter, r *htt
 		err := decoder.Decode(&usersToRecover)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to decode request in recover users handler", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, common.NewBadRequestError(common.InvalidRequestBodyCode, err), ctx)
 			return
 		}
-		if bp.recoveryState != RecoveryRunningState {
-			bp.recoveryState = RecoveryRunningState
-			go bp.recovery(usersToRecover.ConnectionProperties, ctx)
+		if invalid := bp.invalidPasswordUsers(usersToRecover.ConnectionProperties); len(invalid) > 0 {
+			common.WriteError(w, common.NewBadRequestError(common.InvalidPasswordCode,
+				fmt.Errorf("passwords of %v users do not match password validation regex of the cluster", invalid)), ctx)
+			return
+		}
+		resume, _ := strconv.ParseBool(r.URL.Query().Get("resume"))
+		job, err := bp.startRecovery(usersToRecover.ConnectionProperties, resume, ctx)
+		if err != nil {
+			common.WriteError(w, err, ctx)
+			return
+		}
+		responseBody, err := json.Marshal(job)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to marshal response to JSON", slog.Any("error", err))
+			common.WriteError(w, err, ctx)
+			return
 		}
 		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
 	}
 }
 
+// GetRecoveryStateHandler returns only the state of the latest recovery job as plain text, it is used by DBaaS aggregator.
 func (bp *BaseProvider) GetRecoveryStateHandler() func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		responseBody := []byte(bp.recoveryState)
+		responseBody := []byte(bp.getRecoveryState())
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write(responseBody)
 	}
 }
 
-func (bp *BaseProvider) recovery(connectionProperties []common.ConnectionProperties, ctx context.Context) {
+// startRecovery starts new recovery job unless another one is running, in that case the running job is returned.
+// If resume is requested, users which failed in the latest failed job are retried and the job is continued from its
+// resume offset.
+func (bp *BaseProvider) startRecovery(connectionProperties []common.ConnectionProperties, resume bool,
+	ctx context.Context) (*RecoveryJob, error) {
+	bp.recoveryMutex.Lock()
+	if bp.recoveryJob != nil && bp.recoveryJob.State == RecoveryRunningState {
+		job := bp.recoveryJob.copy()
+		bp.recoveryMutex.Unlock()
+		logger.InfoContext(ctx, fmt.Sprintf("Users recovery '%s' is already running", job.Id))
+		return job, nil
+	}
+	if bp.isShuttingDown() {
+		bp.recoveryMutex.Unlock()
+		return nil, common.NewError(http.StatusServiceUnavailable, common.ShuttingDownCode,
+			errors.New("adapter is shutting down, users recovery cannot be started"))
+	}
+	if resume {
+		if err := bp.recoveryJob.checkResumable(connectionProperties); err != nil {
+			bp.recoveryMutex.Unlock()
+			return nil, err
+		}
+		bp.recoveryJob.State = RecoveryRunningState
+		bp.recoveryJob.FinishedAt = nil
+	} else {
+		bp.recoveryJob = newRecoveryJob(connectionProperties)
+	}
+	bp.setRecoveryState(RecoveryRunningState)
+	job := bp.recoveryJob.copy()
+	bp.recoveryGroup.Add(1)
+	bp.recoveryMutex.Unlock()
+
+	logger.InfoContext(ctx, fmt.Sprintf("Users recovery '%s' is started for %d users from %d offset, %d failed users are retried",
+		job.Id, job.TotalUsers, job.ResumeOffset, job.FailedUsers))
+	bp.saveRecoveryJob(job, ctx)
+	go bp.recovery(connectionProperties, job.ResumeOffset, job.failedUsers(), ctx)
+	return job, nil
+}
+
+// recovery retries the failed users and then applies users by batches starting from the offset. Failed batches do not
+// stop the recovery, users which are rejected by OpenSearch are isolated and reported in the job.
+func (bp *BaseProvider) recovery(connectionProperties []common.ConnectionProperties, offset int,
+	failedUsers map[string]bool, ctx context.Context) {
+	defer bp.recoveryGroup.Done()
+	exceeded, err := bp.exceededQuotas(ctx)
+	if err != nil {
+		logger.WarnContext(ctx, "Unable to check quotas of databases, restrictions of recovered users are applied by the next quota check",
+			slog.Any("error", err))
+	}
 	var changes []Change
-	for _, properties := range connectionProperties {
-		changes = append(changes, Change{
+	var retries []Change
+	for i, properties := range connectionProperties {
+		change := Change{
 			Operation: "add",
 			Path:      fmt.Sprintf("/%s", properties.Username),
-			Value:     bp.getUserContent(properties),
-		})
-	}
-	position := 0
-	var batch []Change
-	for position < len(changes) {
-		if position+batchSize < len(changes) {
-			batch = changes[position : position+batchSize]
-		} else {
-			batch = changes[position:]
-		}
-		logger.DebugContext(ctx, fmt.Sprintf("Current batch size is %d", len(batch)))
-		var err error
-		// 3 attempts to create corresponding patch of users
-		for i := 0; i < 3; i++ {
-			err = bp.patchUsers(batch, ctx)
-			if err == nil {
-				break
-			}
-			time.Sleep(10 * time.Second)
+			Value:     bp.getUserContent(properties, exceeded),
 		}
-		if err != nil {
-			bp.recoveryState = RecoveryFailedState
-			logger.ErrorContext(ctx, fmt.Sprintf("Unable to restore users because of error: %+v", err))
+		changes = append(changes, change)
+		if i < offset && failedUsers[properties.Username] {
+			retries = append(retries, change)
+		}
+	}
+	// retried users already have outcomes, they are replaced and the resume offset is not changed
+	for position := 0; position < len(retries); position += batchSize {
+		if bp.interruptRecovery(fmt.Sprintf("while %d of %d failed users are retried", position, len(retries)), ctx) {
 			return
 		}
-		position += batchSize
+		batch := retries[position:min(position+batchSize, len(retries))]
+		failures := bp.recoverBatch(batch, ctx)
+		bp.updateRecoveryJob(func(job *RecoveryJob) {
+			job.replaceOutcomes(batchOutcomes(batch, failures))
+			if len(failures) > 0 {
+				job.Errors = append(job.Errors, fmt.Sprintf("retry of failed users: %d of %d users are not restored",
+					len(failures), len(batch)))
+			}
+		}, ctx)
+	}
+	for position := offset; position < len(changes); position += batchSize {
+		if bp.interruptRecovery(fmt.Sprintf("at %d offset", position), ctx) {
+			return
+		}
+		end := min(position+batchSize, len(changes))
+		batch := changes[position:end]
+		failures := bp.recoverBatch(batch, ctx)
+		bp.updateRecoveryJob(func(job *RecoveryJob) {
+			job.CompletedBatches++
+			job.ResumeOffset = end
+			for _, outcome := range batchOutcomes(batch, failures) {
+				job.addOutcome(outcome)
+			}
+			if len(failures) > 0 {
+				job.Errors = append(job.Errors, fmt.Sprintf("batch %d: %d of %d users are not restored",
+					position/batchSize+1, len(failures), len(batch)))
+			}
+		}, ctx)
+	}
+	var state string
+	bp.updateRecoveryJob(func(job *RecoveryJob) {
+		state = RecoveryDoneState
+		if job.FailedUsers > 0 {
+			state = RecoveryFailedState
+		}
+		job.finish(state)
+	}, ctx)
+	if state == RecoveryDoneState {
+		logger.InfoContext(ctx, "Users recovery is successfully finished")
+	} else {
+		logger.WarnContext(ctx, "Users recovery is finished, some users are not restored")
+	}
+}
+
+// interruptRecovery finishes the job as failed if the adapter is shutting down and reports whether it is interrupted
+func (bp *BaseProvider) interruptRecovery(progress string, ctx context.Context) bool {
+	if !bp.isShuttingDown() {
+		return false
+	}
+	bp.updateRecoveryJob(func(job *RecoveryJob) {
+		job.finish(RecoveryFailedState)
+		job.Errors = append(job.Errors, fmt.Sprintf("users recovery is interrupted by shutdown %s", progress))
+	}, ctx)
+	logger.WarnContext(ctx, fmt.Sprintf("Users recovery is interrupted by shutdown %s", progress))
+	return true
+}
+
+// recoverBatch applies the batch and returns errors by names of users which are not restored
+func (bp *BaseProvider) recoverBatch(batch []Change, ctx context.Context) map[string]string {
+	logger.DebugContext(ctx, fmt.Sprintf("Current batch size is %d", len(batch)))
+	err := bp.patchUsersWithRetries(batch, ctx)
+	if err == nil {
+		return nil
+	}
+	logger.ErrorContext(ctx, fmt.Sprintf("Unable to restore batch of %d users because of error: %+v", len(batch), err))
+	return bp.isolateFailedUsers(batch, err, ctx)
+}
+
+// batchOutcomes reports users of the batch as restored unless they have failures
+func batchOutcomes(batch []Change, failures map[string]string) []UserRecoveryOutcome {
+	outcomes := make([]UserRecoveryOutcome, 0, len(batch))
+	for _, change := range batch {
+		username := strings.TrimPrefix(change.Path, "/")
+		if failure, failed := failures[username]; failed {
+			outcomes = append(outcomes, UserRecoveryOutcome{Username: username, Status: UserFailedStatus, Error: failure})
+		} else {
+			outcomes = append(outcomes, UserRecoveryOutcome{Username: username, Status: UserRestoredStatus})
+		}
+	}
+	return outcomes
+}
+
+// patchUsersWithRetries applies the batch with 3 attempts, batches rejected by OpenSearch are not retried.
+func (bp *BaseProvider) patchUsersWithRetries(batch []Change, ctx context.Context) error {
+	var err error
+	for i := 0; i < 3; i++ {
+		err = bp.patchUsers(batch, ctx)
+		var rejected *usersBatchRejectedError
+		if err == nil || i == 2 || errors.As(err, &rejected) {
+			break
+		}
+		select {
+		case <-bp.recoveryStop:
+		case <-time.After(10 * time.Second):
+		}
+	}
+	return err
+}
+
+// isolateFailedUsers applies halves of the rejected batch separately until the rejected users are found,
+// it returns errors by names of users which are not restored. If the batch failed not because of its content,
+// all users of the batch are considered as failed.
+func (bp *BaseProvider) isolateFailedUsers(batch []Change, err error, ctx context.Context) map[string]string {
+	failures := make(map[string]string)
+	var rejected *usersBatchRejectedError
+	if len(batch) == 1 || !errors.As(err, &rejected) {
+		for _, change := range batch {
+			failures[strings.TrimPrefix(change.Path, "/")] = err.Error()
+		}
+		return failures
+	}
+	middle := len(batch) / 2
+	for _, half := range [][]Change{batch[:middle], batch[middle:]} {
+		halfErr := bp.patchUsers(half, ctx)
+		if halfErr == nil {
+			continue
+		}
+		for username, failure := range bp.isolateFailedUsers(half, halfErr, ctx) {
+			failures[username] = failure
+		}
+	}
+	return failures
+}
+
+// Shutdown interrupts users recovery after the batch in progress and periodic background processes after the check
+// in progress and waits until they are finished or the context is done.
+func (bp *BaseProvider) Shutdown(ctx context.Context) error {
+	if !bp.isShuttingDown() {
+		close(bp.recoveryStop)
+	}
+	finished := make(chan struct{})
+	go func() {
+		bp.recoveryGroup.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("users recovery or background processes are not finished: %w", ctx.Err())
+	}
+}
+
+func (bp *BaseProvider) isShuttingDown() bool {
+	select {
+	case <-bp.recoveryStop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (bp *BaseProvider) getRecoveryState() string {
+	bp.recoveryMutex.Lock()
+	defer bp.recoveryMutex.Unlock()
+	if bp.recoveryJob == nil {
+		return RecoveryIdleState
+	}
+	return bp.recoveryJob.State
+}
+
+// setRecoveryState exposes the state of the current job in metrics
+func (bp *BaseProvider) setRecoveryState(state string) {
+	metrics.SetUsersRecoveryState(bp.clusterId(), state, RecoveryIdleState, RecoveryRunningState, RecoveryFailedState, RecoveryDoneState)
+}
+
+// invalidPasswordUsers returns users whose passwords would be rejected by the cluster,
+// they are checked before recovery to not fail in the middle of it.
+func (bp *BaseProvider) invalidPasswordUsers(connectionProperties []common.ConnectionProperties) []string {
+	var invalid []string
+	for _, properties := range connectionProperties {
+		if bp.passwordGenerator.Validate(properties.Password) != nil {
+			invalid = append(invalid, properties.Username)
+		}
 	}
-	bp.recoveryState = RecoveryDoneState
-	logger.InfoContext(ctx, "Users recovery is successfully finished")
+	return invalid
 }
 
-func (bp *BaseProvider) getUserContent(properties common.ConnectionProperties) Content {
+// getUserContent returns the user with backend roles of its role type, they are restricted if the database of the user
+// is in exceeded quotas.
+func (bp *BaseProvider) getUserContent(properties common.ConnectionProperties, exceeded map[string]bool) Content {
 	roleType := AdminRoleType
 	if properties.Role != "" {
 		roleType = properties.Role
@@ -115,7 +342,7 @@ func (bp *BaseProvider) getUserContent(properties common.ConnectionProperties) C
 	}
 	return Content{
 		Attributes:   map[string]string{resourcePrefixAttributeName: prefix},
-		BackendRoles: bp.GetBackendRoles(roleType),
+		BackendRoles: bp.quotaBackendRoles(bp.GetBackendRoles(roleType), exceeded[prefix]),
 		Password:     properties.Password,
 	}
 }
@@ -32,7 +32,7 @@ func TestUserContentWithResourcePrefix(t *testing.T) {
 		ResourcePrefix: resourcePrefix,
 		Role:           roleType,
 	}
-	content := bp.getUserContent(connectionProperties)
+	content := bp.getUserContent(connectionProperties, nil)
 	expectedAttributes := map[string]string{resourcePrefixAttributeName: resourcePrefix}
 	expectedBackendRoles := bp.GetBackendRoles(roleType)
 	assert.Equal(t, password, content.Password)
@@ -51,7 +51,7 @@ func TestUserContentWithoutResourcePrefix(t *testing.T) {
 		Password: password,
 		Role:     roleType,
 	}
-	content := bp.getUserContent(connectionProperties)
+	content := bp.getUserContent(connectionProperties, nil)
 	expectedAttributes := map[string]string{resourcePrefixAttributeName: dbName}
 	expectedBackendRoles := bp.GetBackendRoles(roleType)
 	assert.Equal(t, password, content.Password)
@@ -72,7 +72,7 @@ func TestUserContentWithResourcePrefixAndDbName(t *testing.T) {
 		ResourcePrefix: resourcePrefix,
 		Role:           roleType,
 	}
-	content := bp.getUserContent(connectionProperties)
+	content := bp.getUserContent(connectionProperties, nil)
 	expectedAttributes := map[string]string{resourcePrefixAttributeName: resourcePrefix}
 	expectedBackendRoles := bp.GetBackendRoles(roleType)
 	assert.Equal(t, password, content.Password)
@@ -91,7 +91,7 @@ func TestUserContentWithReadOnlyRole(t *testing.T) {
 		ResourcePrefix: resourcePrefix,
 		Role:           roleType,
 	}
-	content := bp.getUserContent(connectionProperties)
+	content := bp.getUserContent(connectionProperties, nil)
 	expectedAttributes := map[string]string{resourcePrefixAttributeName: resourcePrefix}
 	expectedBackendRoles := bp.GetBackendRoles(roleType)
 	assert.Equal(t, password, content.Password)
@@ -110,7 +110,7 @@ func TestUserContentWithDmlRole(t *testing.T) {
 		ResourcePrefix: resourcePrefix,
 		Role:           roleType,
 	}
-	content := bp.getUserContent(connectionProperties)
+	content := bp.getUserContent(connectionProperties, nil)
 	expectedAttributes := map[string]string{resourcePrefixAttributeName: resourcePrefix}
 	expectedBackendRoles := bp.GetBackendRoles(roleType)
 	assert.Equal(t, password, content.Password)
@@ -127,7 +127,7 @@ func TestUserContentWithIsmRole(t *testing.T) {
 		Password: password,
 		Role:     roleType,
 	}
-	content := bp.getUserContent(connectionProperties)
+	content := bp.getUserContent(connectionProperties, nil)
 	expectedBackendRoles := bp.GetBackendRoles(roleType)
 	assert.Equal(t, password, content.Password)
 	assert.EqualValues(t, expectedBackendRoles, content.BackendRoles)
@@ -142,7 +142,7 @@ func TestUserContentWithoutRole(t *testing.T) {
 		Password:       password,
 		ResourcePrefix: resourcePrefix,
 	}
-	content := bp.getUserContent(connectionProperties)
+	content := bp.getUserContent(connectionProperties, nil)
 	expectedAttributes := map[string]string{resourcePrefixAttributeName: resourcePrefix}
 	expectedBackendRoles := bp.GetBackendRoles(AdminRoleType)
 	assert.Equal(t, password, content.Password)
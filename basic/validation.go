@@ -0,0 +1,265 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+)
+
+const (
+	// maxPrefixLength is the same limit which is used for prefixes generated from namespace and microservice name
+	maxPrefixLength = 64
+	// maxIndexNameLength is the maximum length of index name in bytes allowed by OpenSearch
+	maxIndexNameLength = 255
+	// indexNameForbiddenCharacters are characters which OpenSearch does not allow in index names
+	indexNameForbiddenCharacters = ` \/*?"<>|,#:`
+	// indexNameForbiddenStart are characters index name cannot start with
+	indexNameForbiddenStart = "-_+."
+)
+
+// Violation describes one problem found in the request.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// decodeRequest decodes JSON body into the target and returns violations for all fields which are not defined in the target.
+func decodeRequest(body io.Reader, target interface{}) ([]Violation, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, target); err != nil {
+		return nil, err
+	}
+	return unknownFields(data, reflect.TypeOf(target).Elem(), ""), nil
+}
+
+func unknownFields(data []byte, targetType reflect.Type, path string) []Violation {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	known := make(map[string]reflect.Type, targetType.NumField())
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			known[name] = field.Type
+		}
+	}
+	var violations []Violation
+	for name, value := range fields {
+		fieldType, ok := known[name]
+		if !ok {
+			violations = append(violations, Violation{Field: path + name, Message: "unknown field"})
+			continue
+		}
+		if fieldType.Kind() == reflect.Struct {
+			violations = append(violations, unknownFields(value, fieldType, path+name+".")...)
+		}
+	}
+	slices.SortFunc(violations, func(a, b Violation) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return violations
+}
+
+func (bp BaseProvider) validateDbCreateRequest(request DbCreateRequest) []Violation {
+	var violations []Violation
+	if classifier, ok := request.Metadata[classifierMetadataKey]; ok {
+		if classifierMap, ok := classifier.(map[string]interface{}); ok {
+			violations = append(violations, validateStringValue("metadata.classifier.namespace", classifierMap["namespace"])...)
+			violations = append(violations, validateStringValue("metadata.classifier.microserviceName", classifierMap["microserviceName"])...)
+		} else {
+			violations = append(violations, Violation{Field: "metadata.classifier", Message: "must be an object"})
+		}
+	}
+	violations = append(violations, validateStringValue("metadata.microserviceName", request.Metadata["microserviceName"])...)
+
+	for i, kind := range request.Settings.CreateOnly {
+		if kind != common.UserKind && kind != common.IndexKind {
+			violations = append(violations, Violation{
+				Field:   fmt.Sprintf("settings.createOnly[%d]", i),
+				Message: fmt.Sprintf("unsupported resource kind '%s', allowed values are '%s' and '%s'", kind, common.UserKind, common.IndexKind),
+			})
+		}
+	}
+	if request.Settings.IndexSettings != nil {
+		if _, ok := request.Settings.IndexSettings.(map[string]interface{}); !ok {
+			violations = append(violations, Violation{Field: "settings.indexSettings", Message: "must be an object"})
+		}
+	}
+
+	violations = append(violations, bp.validateAdditionalPermissions(request.Settings)...)
+
+	violations = append(violations, bp.validatePassword(request.Password)...)
+	violations = append(violations, validatePrefix("namePrefix", request.NamePrefix)...)
+	if request.DbName != "" {
+		violations = append(violations, validateIndexNamePart("dbName", request.DbName)...)
+		prefixLength := len(request.NamePrefix)
+		if prefixLength == 0 {
+			prefixLength = maxPrefixLength
+		}
+		if prefixLength+1+len(request.DbName) > maxIndexNameLength {
+			violations = append(violations, Violation{
+				Field:   "dbName",
+				Message: fmt.Sprintf("index name built from prefix and database name must not be longer than %d bytes", maxIndexNameLength),
+			})
+		}
+	}
+	return violations
+}
+
+// validateAdditionalPermissions checks that additional permissions are requested for role types of users created
+// for the database, they are granted on indices of the resource prefix only, and cluster permissions are read-only.
+func (bp BaseProvider) validateAdditionalPermissions(settings Settings) []Violation {
+	if len(settings.AdditionalPermissions) == 0 {
+		return nil
+	}
+	var violations []Violation
+	if !settings.ResourcePrefix {
+		violations = append(violations, Violation{Field: "settings.additionalPermissions", Message: "requires 'resourcePrefix' setting"})
+	}
+	if len(settings.CreateOnly) > 0 && !slices.Contains(settings.CreateOnly, common.UserKind) {
+		violations = append(violations, Violation{
+			Field:   "settings.additionalPermissions",
+			Message: fmt.Sprintf("requires '%s' resource kind in 'createOnly' setting", common.UserKind),
+		})
+	}
+	roleTypes := bp.GetSupportedRoleTypes()
+	if bp.ApiVersion == common.ApiV1 {
+		roleTypes = []string{AdminRoleType}
+	}
+	for roleType, permissions := range settings.AdditionalPermissions {
+		field := fmt.Sprintf("settings.additionalPermissions.%s", roleType)
+		if !slices.Contains(roleTypes, roleType) {
+			violations = append(violations, Violation{
+				Field:   field,
+				Message: fmt.Sprintf("unsupported role '%s', allowed values are %v", roleType, roleTypes),
+			})
+		}
+		if len(permissions.ClusterPermissions) == 0 && len(permissions.IndexPermissions) == 0 {
+			violations = append(violations, Violation{Field: field, Message: "cluster or index permissions must be specified"})
+		}
+		for i, permission := range permissions.IndexPermissions {
+			if strings.TrimSpace(permission) == "" {
+				violations = append(violations, Violation{Field: fmt.Sprintf("%s.indexPermissions[%d]", field, i), Message: "must not be empty"})
+			}
+		}
+		for i, permission := range permissions.ClusterPermissions {
+			permissionField := fmt.Sprintf("%s.clusterPermissions[%d]", field, i)
+			if strings.TrimSpace(permission) == "" {
+				violations = append(violations, Violation{Field: permissionField, Message: "must not be empty"})
+			} else if strings.Contains(permission, "*") || !isAllowedAdditionalClusterPermission(permission) {
+				violations = append(violations, Violation{
+					Field: permissionField,
+					Message: fmt.Sprintf("unsupported cluster permission '%s', allowed values are actions without wildcards matching %v",
+						permission, allowedAdditionalClusterPermissions),
+				})
+			}
+		}
+	}
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return violations
+}
+
+func (bp BaseProvider) validateUserCreateRequest(request dao.UserCreateRequest) []Violation {
+	var violations []Violation
+	if request.Role != "" && !slices.Contains(bp.GetSupportedRoleTypes(), request.Role) {
+		violations = append(violations, Violation{
+			Field:   "role",
+			Message: fmt.Sprintf("unsupported role '%s', allowed values are %v", request.Role, bp.GetSupportedRoleTypes()),
+		})
+	}
+	violations = append(violations, bp.validatePassword(request.Password)...)
+	// dbName of users created for the resource prefix ends with `*`, so the prefix itself is validated
+	violations = append(violations, validateIndexName("dbName", strings.TrimSuffix(request.DbName, "*"))...)
+	return violations
+}
+
+func (bp BaseProvider) validatePassword(password string) []Violation {
+	if password == "" {
+		return nil
+	}
+	if err := bp.passwordGenerator.Validate(password); err != nil {
+		return []Violation{{Field: "password", Message: err.Error()}}
+	}
+	return nil
+}
+
+func validatePrefix(field string, prefix string) []Violation {
+	if prefix == "" {
+		return nil
+	}
+	violations := validateIndexNamePart(field, prefix)
+	if strings.ContainsAny(prefix[:1], indexNameForbiddenStart) {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf("must not start with any of '%s'", indexNameForbiddenStart)})
+	}
+	if len(prefix) > maxPrefixLength {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf("must not be longer than %d bytes", maxPrefixLength)})
+	}
+	return violations
+}
+
+// validateIndexName checks the name of existing index, so only rules of OpenSearch are applied to it
+func validateIndexName(field string, name string) []Violation {
+	violations := validateIndexNamePart(field, name)
+	if len(name) > maxIndexNameLength {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf("must not be longer than %d bytes", maxIndexNameLength)})
+	}
+	return violations
+}
+
+func validateIndexNamePart(field string, value string) []Violation {
+	var violations []Violation
+	if value != strings.ToLower(value) {
+		violations = append(violations, Violation{Field: field, Message: "must be lowercase"})
+	}
+	if strings.ContainsAny(value, indexNameForbiddenCharacters) {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf("must not contain any of '%s'", indexNameForbiddenCharacters)})
+	}
+	return violations
+}
+
+func validateStringValue(field string, value interface{}) []Violation {
+	if value == nil {
+		return nil
+	}
+	if _, ok := value.(string); !ok {
+		return []Violation{{Field: field, Message: "must be a string"}}
+	}
+	return nil
+}
+
+func newValidationError(violations []Violation) error {
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, fmt.Sprintf("%s: %s", violation.Field, violation.Message))
+	}
+	err := errors.New("request validation failed: " + strings.Join(messages, "; "))
+	return common.NewBadRequestError(common.ValidationErrorCode, err).WithDetails(violations)
+}
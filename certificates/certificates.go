@@ -0,0 +1,271 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/dbaas-opensearch-adapter/metrics"
+)
+
+var (
+	logger = common.GetLogger()
+
+	reloadInterval = time.Duration(common.GetIntEnv("TLS_RELOAD_INTERVAL_MS", 30000)) * time.Millisecond
+
+	// ErrNoCertificates is returned when watched files do not contain any valid certificate
+	ErrNoCertificates = errors.New("no valid certificates found")
+)
+
+// watcher reloads certificates when the content of watched files is changed.
+// Files are compared by content instead of modification time, because Kubernetes updates mounted secrets by symlink swap.
+type watcher struct {
+	// cluster is the identifier of OpenSearch cluster the certificates belong to, it is empty for shared certificates
+	cluster string
+	name    string
+	files   func() []string
+	load    func() error
+	digest  [sha256.Size]byte
+}
+
+// check loads certificates if the content of watched files differs from the loaded one and reports whether they are reloaded.
+func (w *watcher) check() (bool, error) {
+	digest, err := filesDigest(w.files())
+	if err != nil {
+		return false, err
+	}
+	if digest == w.digest {
+		return false, nil
+	}
+	if err = w.load(); err != nil {
+		return false, err
+	}
+	w.digest = digest
+	return true, nil
+}
+
+// Watch checks watched files with TLS_RELOAD_INTERVAL_MS interval until the context is done.
+// Failed reload keeps previously loaded certificates and is retried on the next check.
+func (w *watcher) Watch(ctx context.Context) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := w.check()
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to reload '%s' TLS certificates, previous ones are used: %v", w.name, err))
+				continue
+			}
+			if reloaded {
+				logger.Info(fmt.Sprintf("TLS certificates '%s' are successfully reloaded", w.name))
+				metrics.ObserveTLSReload(w.cluster, w.name)
+			}
+		}
+	}
+}
+
+func filesDigest(paths []string) ([sha256.Size]byte, error) {
+	hash := sha256.New()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return [sha256.Size]byte{}, err
+		}
+		hash.Write([]byte(path))
+		hash.Write(data)
+	}
+	var digest [sha256.Size]byte
+	copy(digest[:], hash.Sum(nil))
+	return digest, nil
+}
+
+// Files returns function which lists the given files.
+func Files(paths ...string) func() []string {
+	return func() []string {
+		return paths
+	}
+}
+
+// DirectoryFiles returns function which lists files of the given directory, subdirectories are skipped.
+func DirectoryFiles(dir string) func() []string {
+	return func() []string {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+		var paths []string
+		for _, entry := range entries {
+			if common.IsNotDir(entry) {
+				paths = append(paths, filepath.Join(dir, entry.Name()))
+			}
+		}
+		return paths
+	}
+}
+
+// KeyPair is server or client certificate with private key which can be reloaded without recreation of listeners and clients.
+type KeyPair struct {
+	watcher
+	certificate atomic.Pointer[tls.Certificate]
+}
+
+func NewKeyPair(cluster string, name string, certFile string, keyFile string) (*KeyPair, error) {
+	keyPair := &KeyPair{}
+	keyPair.watcher = watcher{
+		cluster: cluster,
+		name:    name,
+		files:   Files(certFile, keyFile),
+		load: func() error {
+			certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return err
+			}
+			keyPair.certificate.Store(&certificate)
+			return nil
+		},
+	}
+	if _, err := keyPair.check(); err != nil {
+		return nil, err
+	}
+	return keyPair, nil
+}
+
+// GetCertificate returns the latest loaded certificate, it is intended to be used as tls.Config.GetCertificate.
+func (kp *KeyPair) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return kp.certificate.Load(), nil
+}
+
+// GetClientCertificate returns the latest loaded certificate, it is intended to be used as tls.Config.GetClientCertificate.
+func (kp *KeyPair) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return kp.certificate.Load(), nil
+}
+
+// CAPool is a pool of root CA certificates which can be reloaded without recreation of HTTP clients.
+type CAPool struct {
+	watcher
+	pool atomic.Pointer[x509.CertPool]
+}
+
+// NewCAPool loads certificates from the listed files, absent files are skipped.
+// ErrNoCertificates is returned if there is no valid certificate in the files.
+func NewCAPool(cluster string, name string, files func() []string) (*CAPool, error) {
+	caPool := &CAPool{}
+	caPool.watcher = watcher{
+		cluster: cluster,
+		name:    name,
+		files:   files,
+		load: func() error {
+			pool := x509.NewCertPool()
+			appended := 0
+			for _, path := range files() {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					if errors.Is(err, os.ErrNotExist) {
+						continue
+					}
+					return err
+				}
+				if pool.AppendCertsFromPEM(data) {
+					appended++
+				}
+			}
+			if appended == 0 {
+				return ErrNoCertificates
+			}
+			caPool.pool.Store(pool)
+			return nil
+		},
+	}
+	if _, err := caPool.check(); err != nil {
+		return nil, err
+	}
+	return caPool, nil
+}
+
+func (cp *CAPool) Pool() *x509.CertPool {
+	return cp.pool.Load()
+}
+
+// VerifyConnection verifies server certificate chain and host name with the latest loaded pool. Go does not keep
+// IP addresses in tls.ConnectionState.ServerName, so connections without server name are rejected, they must be
+// dialed by ClientTransport which verifies the dialed host.
+func (cp *CAPool) VerifyConnection(state tls.ConnectionState) error {
+	if state.ServerName == "" {
+		return errors.New("server name is unknown, certificate cannot be verified")
+	}
+	return cp.verify(state, state.ServerName)
+}
+
+func (cp *CAPool) verify(state tls.ConnectionState, host string) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server did not present any certificate")
+	}
+	options := x509.VerifyOptions{
+		DNSName:       host,
+		Intermediates: x509.NewCertPool(),
+		Roots:         cp.Pool(),
+	}
+	for _, certificate := range state.PeerCertificates[1:] {
+		options.Intermediates.AddCert(certificate)
+	}
+	_, err := state.PeerCertificates[0].Verify(options)
+	return err
+}
+
+// ClientTLSConfig returns client TLS configuration which verifies servers with the latest loaded pool.
+// Default verification is replaced by VerifyConnection, because tls.Config.RootCAs cannot be changed after the first use.
+func (cp *CAPool) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyConnection:   cp.VerifyConnection,
+	}
+}
+
+// ClientTransport returns HTTP transport which verifies server certificates against the dialed host, either host name
+// or IP address, with the latest loaded pool. Changes of TLSClientConfig of the transport apply to new connections.
+func (cp *CAPool) ClientTransport() *http.Transport {
+	transport := &http.Transport{TLSClientConfig: cp.ClientTLSConfig()}
+	transport.DialTLSContext = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		config := transport.TLSClientConfig.Clone()
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return cp.verify(state, host)
+		}
+		dialer := &tls.Dialer{Config: config}
+		return dialer.DialContext(ctx, network, address)
+	}
+	return transport
+}
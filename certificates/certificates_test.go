@@ -0,0 +1,182 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateCertificate(t *testing.T, commonName string) ([]byte, []byte) {
+	return generateCertificateForIP(t, commonName, "127.0.0.1")
+}
+
+func generateCertificateForIP(t *testing.T, commonName string, ip string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP(ip)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestKeyPairReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	cert, key := generateCertificate(t, "first")
+	require.NoError(t, os.WriteFile(certFile, cert, 0600))
+	require.NoError(t, os.WriteFile(keyFile, key, 0600))
+
+	keyPair, err := NewKeyPair("", "server", certFile, keyFile)
+	require.NoError(t, err)
+	certificate, _ := keyPair.GetCertificate(nil)
+	leaf, _ := x509.ParseCertificate(certificate.Certificate[0])
+	assert.Equal(t, "first", leaf.Subject.CommonName)
+
+	reloaded, err := keyPair.check()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// key does not match certificate during rotation, previous certificate is kept
+	cert, key = generateCertificate(t, "second")
+	require.NoError(t, os.WriteFile(certFile, cert, 0600))
+	_, err = keyPair.check()
+	assert.Error(t, err)
+	certificate, _ = keyPair.GetCertificate(nil)
+	leaf, _ = x509.ParseCertificate(certificate.Certificate[0])
+	assert.Equal(t, "first", leaf.Subject.CommonName)
+
+	require.NoError(t, os.WriteFile(keyFile, key, 0600))
+	reloaded, err = keyPair.check()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	certificate, _ = keyPair.GetCertificate(nil)
+	leaf, _ = x509.ParseCertificate(certificate.Certificate[0])
+	assert.Equal(t, "second", leaf.Subject.CommonName)
+}
+
+func TestCAPoolReload(t *testing.T) {
+	serverCert, serverKey := generateCertificate(t, "server")
+	keyPair, err := tlsKeyPair(t, serverCert, serverKey)
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		TLSConfig: &tls.Config{GetCertificate: keyPair.GetCertificate},
+	}
+	go func() {
+		_ = server.ServeTLS(listener, "", "")
+	}()
+	defer server.Close()
+	url := "https://" + listener.Addr().String()
+
+	dir := t.TempDir()
+	otherCert, _ := generateCertificate(t, "other")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), otherCert, 0600))
+	caPool, err := NewCAPool("", "opensearch", DirectoryFiles(dir))
+	require.NoError(t, err)
+	client := &http.Client{Transport: caPool.ClientTransport()}
+
+	_, err = client.Get(url)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), serverCert, 0600))
+	reloaded, err := caPool.check()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	client.CloseIdleConnections()
+	response, err := client.Get(url)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestCAPoolVerifiesIPAddress(t *testing.T) {
+	serverCert, serverKey := generateCertificateForIP(t, "server", "10.0.0.1")
+	keyPair, err := tlsKeyPair(t, serverCert, serverKey)
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		TLSConfig: &tls.Config{GetCertificate: keyPair.GetCertificate},
+	}
+	go func() {
+		_ = server.ServeTLS(listener, "", "")
+	}()
+	defer server.Close()
+	url := "https://" + listener.Addr().String()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), serverCert, 0600))
+	caPool, err := NewCAPool("", "opensearch", DirectoryFiles(dir))
+	require.NoError(t, err)
+
+	_, err = (&http.Client{Transport: caPool.ClientTransport()}).Get(url)
+	assert.ErrorContains(t, err, "127.0.0.1")
+	_, err = (&http.Client{Transport: &http.Transport{TLSClientConfig: caPool.ClientTLSConfig()}}).Get(url)
+	assert.ErrorContains(t, err, "server name is unknown")
+}
+
+func TestCAPoolWithoutCertificates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), []byte("not a certificate"), 0600))
+	_, err := NewCAPool("", "opensearch", DirectoryFiles(dir))
+	assert.ErrorIs(t, err, ErrNoCertificates)
+
+	_, err = NewCAPool("", "curator", Files(filepath.Join(dir, "absent.crt")))
+	assert.ErrorIs(t, err, ErrNoCertificates)
+}
+
+func tlsKeyPair(t *testing.T, cert []byte, key []byte) (*KeyPair, error) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), cert, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), key, 0600))
+	return NewKeyPair("", "server", filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+}
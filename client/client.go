@@ -16,17 +16,16 @@ package client
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"os"
 	"strings"
 
+	"github.com/Netcracker/dbaas-opensearch-adapter/certificates"
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
 	"github.com/Netcracker/dbaas-opensearch-adapter/health"
 )
@@ -63,7 +62,7 @@ type Smoke struct {
 }
 
 func NewAdapterClient(proto string, host string, port int, username, password string) *AdapterClient {
-	httpClient := ConfigureClient()
+	httpClient := ConfigureClient(context.Background())
 	return &AdapterClient{
 		proto:      proto,
 		host:       host,
@@ -195,38 +194,32 @@ func (smoke Smoke) dropDatabase() error {
 	return nil
 }
 
-func ConfigureClient() *http.Client {
-	return ConfigureHttpClient([]string{certificateFilePath})
+func ConfigureClient(ctx context.Context) *http.Client {
+	return ConfigureHttpClient(ctx, "", "aggregator", []string{certificateFilePath})
 }
 
-func ConfigureCuratorClient() *http.Client {
-	return ConfigureHttpClient([]string{certificateFilePath, curatorCertificateFilePath})
+// ConfigureCuratorClient returns HTTP client of curator of the cluster, CA certificate of curator is read from
+// the given file or from the default one if it is empty.
+func ConfigureCuratorClient(ctx context.Context, cluster string, caCertFile string) *http.Client {
+	if caCertFile == "" {
+		caCertFile = curatorCertificateFilePath
+	}
+	return ConfigureHttpClient(ctx, cluster, "curator", []string{certificateFilePath, caCertFile})
 }
 
-func ConfigureHttpClient(certPaths []string) *http.Client {
+// ConfigureHttpClient returns HTTP client which trusts CA certificates from the given paths,
+// certificates are reloaded when the files are changed until the context is done.
+func ConfigureHttpClient(ctx context.Context, cluster string, name string, certPaths []string) *http.Client {
 	httpClient := &http.Client{}
-	caCertPool := x509.NewCertPool()
-	successfullyAppendedCerts := 0
-	for _, certPath := range certPaths {
-		if _, err := os.Stat(certPath); !errors.Is(err, os.ErrNotExist) {
-			caCert, readErr := os.ReadFile(certPath)
-			if readErr != nil {
-				logger.Error(fmt.Sprintf("Unable to read certificates by %s path", certPath), slog.Any("error", readErr))
-				continue
-			}
-			if caCertPool.AppendCertsFromPEM(caCert) {
-				successfullyAppendedCerts++
-			}
+	caPool, err := certificates.NewCAPool(cluster, name, certificates.Files(certPaths...))
+	if err != nil {
+		if !errors.Is(err, certificates.ErrNoCertificates) {
+			logger.Error(fmt.Sprintf("Unable to read certificates by %v paths", certPaths), slog.Any("error", err))
 		}
-	}
-	if successfullyAppendedCerts == 0 {
 		logger.Info("Cannot load valid TLS certificates. Using client without TLS")
 		return httpClient
 	}
-	httpClient.Transport = &http.Transport{
-		TLSClientConfig: &tls.Config{
-			RootCAs: caCertPool,
-		},
-	}
+	httpClient.Transport = caPool.ClientTransport()
+	go caPool.Watch(ctx)
 	return httpClient
 }
@@ -15,16 +15,23 @@
 package cluster
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
-	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Netcracker/dbaas-opensearch-adapter/certificates"
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/dbaas-opensearch-adapter/metrics"
 	"github.com/opensearch-project/opensearch-go"
 	"github.com/opensearch-project/opensearch-go/opensearchapi"
 )
@@ -32,60 +39,134 @@ import (
 var logger = common.GetLogger()
 
 type Opensearch struct {
+	// Id identifies the cluster in metrics, it is the physical database identifier of the cluster
+	Id       string
 	Host     string
 	Port     int
 	Protocol string
-	Health   common.ComponentHealth
-	Client   common.Client
+	// Nodes are URLs of OpenSearch nodes which are advertised in connection properties if AdvertiseNodes is enabled
+	Nodes          []string
+	AdvertiseNodes bool
+	Health         common.ComponentHealth
+	Client         common.Client
 }
 
-const trustCertsFolder = "/trusted-certs"
+// Config describes how the adapter connects to OpenSearch.
+type Config struct {
+	// Id identifies the cluster in metrics, it is the physical database identifier of the cluster
+	Id string
+	// Host, Port and Protocol define the service address of OpenSearch
+	Host     string
+	Port     int
+	Protocol string
+	Username string
+	Password string
+	// ClientCertFile and ClientKeyFile are used for authentication instead of username and password if they exist
+	ClientCertFile string
+	ClientKeyFile  string
+	// Nodes are URLs of OpenSearch nodes, the service address is used if they are not specified
+	Nodes                 []string
+	AdvertiseNodes        bool
+	DiscoverNodesOnStart  bool
+	DiscoverNodesInterval time.Duration
+	// MaxRetries is the number of retries of requests failed with connection errors or, for idempotent methods,
+	// with 5xx status codes
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, it is doubled for each next retry up to maxRetryBackoff
+	RetryBackoff time.Duration
+}
+
+const (
+	trustCertsFolder = "/trusted-certs"
+	maxRetryBackoff  = 10 * time.Second
+)
 
-func NewOpensearch(host string, port int, protocol string, username string, password string) *Opensearch {
-	address := fmt.Sprintf("%s://%s:%d", protocol, host, port)
-	logger.Info(fmt.Sprintf("Creating new OpenSearch on '%s' address", address))
+var retryOnStatus = []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// idempotentMethods are methods of requests which are retried on retryOnStatus statuses and on connection errors,
+// other requests could be already applied by OpenSearch when it fails, so they are retried only if the connection
+// is not established
+var idempotentMethods = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete}
+
+// ParseNodes splits comma-separated list of node URLs, empty entries are skipped.
+func ParseNodes(nodes string) []string {
+	var result []string
+	for _, node := range strings.Split(nodes, ",") {
+		if node = strings.TrimSpace(node); node != "" {
+			result = append(result, strings.TrimSuffix(node, "/"))
+		}
+	}
+	return result
+}
+
+// Addresses returns URLs which are used by the client, the service address is used if nodes are not specified.
+func (c Config) Addresses() []string {
+	if len(c.Nodes) > 0 {
+		return c.Nodes
+	}
+	return []string{fmt.Sprintf("%s://%s:%d", c.Protocol, c.Host, c.Port)}
+}
+
+func (c Config) retryBackoff(attempt int) time.Duration {
+	backoff := c.RetryBackoff
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// NewOpensearch creates client of OpenSearch. If client certificate and key are provided and exist, the client
+// authenticates with the certificate, otherwise basic authentication with username and password is used.
+// Certificates are reloaded until the context is done.
+func NewOpensearch(ctx context.Context, clusterConfig Config) *Opensearch {
+	addresses := clusterConfig.Addresses()
+	logger.Info(fmt.Sprintf("Creating new OpenSearch on %v addresses", addresses))
 
 	var transport *http.Transport
-	if strings.EqualFold(protocol, common.Https) {
-		certsDir, err := os.ReadDir(trustCertsFolder)
-		if err != nil || len(certsDir) == 0 {
-			logger.Info(fmt.Sprintf("Cannot load trusted TLS certificates from path '%s'. InsecureSkipVerify is used.", trustCertsFolder))
+	if strings.EqualFold(clusterConfig.Protocol, common.Https) {
+		caPool, err := certificates.NewCAPool(clusterConfig.Id, "opensearch", certificates.DirectoryFiles(trustCertsFolder))
+		if err != nil {
+			if !errors.Is(err, certificates.ErrNoCertificates) {
+				logger.Error(fmt.Sprintf("Failed to read trusted certificates from path '%s': %+v", trustCertsFolder, err))
+				panic(err)
+			}
+			logger.Warn(fmt.Sprintf("Cannot load valid trusted TLS certificates from path '%s'. InsecureSkipVerify mode is used. Do not use this mode in production.", trustCertsFolder))
 			transport = &http.Transport{
 				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 			}
 		} else {
-			certs := x509.NewCertPool()
-			successfullyAppendedCerts := 0
-			for _, cert := range certsDir {
-				if common.IsNotDir(cert) {
-					pemData, err := os.ReadFile(fmt.Sprintf("%s/%s", trustCertsFolder, cert.Name()))
-					if err != nil {
-						logger.Error(fmt.Sprintf("Failed to read certificate '%s': %+v", cert.Name(), err))
-						panic(err)
-					}
-					if certs.AppendCertsFromPEM(pemData) {
-						successfullyAppendedCerts++
-					}
-					logger.Info(fmt.Sprintf("Trusted certificate '%s' was added to client", cert.Name()))
-				}
-			}
-			if successfullyAppendedCerts == 0 {
-				logger.Warn(fmt.Sprintf("Cannot load valid trusted TLS certificates from path '%s'. InsecureSkipVerify mode is used. Do not use this mode in production.", trustCertsFolder))
-				transport = &http.Transport{
-					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-				}
-			} else {
-				transport = &http.Transport{
-					TLSClientConfig: &tls.Config{RootCAs: certs},
-				}
-			}
+			logger.Info(fmt.Sprintf("Trusted certificates from path '%s' were added to client", trustCertsFolder))
+			transport = caPool.ClientTransport()
+			go caPool.Watch(ctx)
 		}
 	}
 
 	config := opensearch.Config{
-		Addresses: []string{address},
-		Username:  username,
-		Password:  password,
+		Addresses:             addresses,
+		Username:              clusterConfig.Username,
+		Password:              clusterConfig.Password,
+		DiscoverNodesOnStart:  clusterConfig.DiscoverNodesOnStart,
+		DiscoverNodesInterval: clusterConfig.DiscoverNodesInterval,
+		// requests are retried by retryingClient, because OpenSearch client retries them regardless of method
+		DisableRetry: true,
+	}
+	if clientCertificateExists(clusterConfig.ClientCertFile, clusterConfig.ClientKeyFile) {
+		if transport == nil {
+			logger.Warn(fmt.Sprintf("Client certificate '%s' is ignored, because '%s' protocol is used for OpenSearch", clusterConfig.ClientCertFile, clusterConfig.Protocol))
+		} else {
+			keyPair, err := certificates.NewKeyPair(clusterConfig.Id, "opensearch-client", clusterConfig.ClientCertFile, clusterConfig.ClientKeyFile)
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to load OpenSearch client certificate '%s'", clusterConfig.ClientCertFile), slog.Any("error", err))
+				panic(err)
+			}
+			logger.Info(fmt.Sprintf("Client certificate '%s' is used for authentication in OpenSearch", clusterConfig.ClientCertFile))
+			transport.TLSClientConfig.GetClientCertificate = keyPair.GetClientCertificate
+			go keyPair.Watch(ctx)
+			config.Username = ""
+			config.Password = ""
+		}
+	} else {
+		logger.Info("Basic authentication is used for OpenSearch")
 	}
 	if transport != nil {
 		config.Transport = transport
@@ -97,11 +178,14 @@ func NewOpensearch(host string, port int, protocol string, username string, pass
 	}
 
 	service := &Opensearch{
-		Host:     host,
-		Port:     port,
-		Protocol: protocol,
-		Health:   common.ComponentHealth{Status: common.Up},
-		Client:   oc,
+		Id:             clusterConfig.Id,
+		Host:           clusterConfig.Host,
+		Port:           clusterConfig.Port,
+		Protocol:       clusterConfig.Protocol,
+		Nodes:          clusterConfig.Nodes,
+		AdvertiseNodes: clusterConfig.AdvertiseNodes,
+		Health:         common.ComponentHealth{Status: common.Up},
+		Client:         metrics.NewInstrumentedClient(clusterConfig.Id, &retryingClient{Client: oc, config: clusterConfig}),
 	}
 
 	service.Health.Status = service.GetHealth(context.Background())
@@ -109,6 +193,68 @@ func NewOpensearch(host string, port int, protocol string, username string, pass
 	return service
 }
 
+// retryingClient retries requests which cannot connect to the node and idempotent requests failed with other connection
+// errors or with retryOnStatus statuses, each attempt is sent to the next live node.
+type retryingClient struct {
+	*opensearch.Client
+	config Config
+}
+
+func (c *retryingClient) Perform(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if c.config.MaxRetries > 0 && req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+	}
+	for attempt := 0; ; attempt++ {
+		if body != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		response, err := c.Client.Perform(req)
+		if attempt == c.config.MaxRetries || !shouldRetry(req, response, err) {
+			return response, err
+		}
+		if response != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(c.config.retryBackoff(attempt + 1)):
+		}
+	}
+}
+
+func shouldRetry(req *http.Request, response *http.Response, err error) bool {
+	idempotent := slices.Contains(idempotentMethods, req.Method)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			// the request is not sent, so it is safe to retry it regardless of method
+			return !opErr.Timeout()
+		}
+		var netErr net.Error
+		return idempotent && (errors.Is(err, io.EOF) || errors.As(err, &netErr) && !netErr.Timeout())
+	}
+	return idempotent && slices.Contains(retryOnStatus, response.StatusCode)
+}
+
+func clientCertificateExists(certFile string, keyFile string) bool {
+	if certFile == "" || keyFile == "" {
+		return false
+	}
+	for _, path := range []string{certFile, keyFile} {
+		if _, err := os.Stat(path); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func (o Opensearch) GetHealth(ctx context.Context) string {
 	healthRequest := opensearchapi.CatHealthRequest{
 		Format: "json",
@@ -0,0 +1,260 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedRequest struct {
+	commonName    string
+	authorization bool
+}
+
+// startOpensearchStub starts TLS server which answers cluster info and health requests and records how requests are authenticated
+func startOpensearchStub(t *testing.T, clientCAs *x509.CertPool) (string, int, chan receivedRequest) {
+	requests := make(chan receivedRequest, 10)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := receivedRequest{}
+		if len(r.TLS.PeerCertificates) > 0 {
+			request.commonName = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		_, _, request.authorization = r.BasicAuth()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"version":{"number":"2.11.0","distribution":"opensearch"}}`))
+			return
+		}
+		requests <- request
+		_, _ = w.Write([]byte(`[{"status":"green"}]`))
+	}))
+	server.TLS = &tls.Config{ClientCAs: clientCAs, ClientAuth: tls.VerifyClientCertIfGiven}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, portNumber, requests
+}
+
+func writeClientCertificate(t *testing.T, commonName string) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile, certificate
+}
+
+func TestOpensearchWithClientCertificate(t *testing.T) {
+	certFile, keyFile, certificate := writeClientCertificate(t, "admin")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(certificate)
+	host, port, requests := startOpensearchStub(t, clientCAs)
+
+	opensearch := NewOpensearch(context.Background(), Config{
+		Host:           host,
+		Port:           port,
+		Protocol:       common.Https,
+		Username:       "opensearch",
+		Password:       "change",
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+	})
+	assert.Equal(t, common.Up, opensearch.Health.Status)
+	request := <-requests
+	assert.Equal(t, "admin", request.commonName)
+	assert.False(t, request.authorization)
+}
+
+func TestOpensearchWithBasicAuthFallback(t *testing.T) {
+	host, port, requests := startOpensearchStub(t, nil)
+
+	opensearch := NewOpensearch(context.Background(), Config{
+		Host:           host,
+		Port:           port,
+		Protocol:       common.Https,
+		Username:       "opensearch",
+		Password:       "change",
+		ClientCertFile: filepath.Join(t.TempDir(), "absent.crt"),
+		ClientKeyFile:  filepath.Join(t.TempDir(), "absent.key"),
+	})
+	assert.Equal(t, common.Up, opensearch.Health.Status)
+	request := <-requests
+	assert.Empty(t, request.commonName)
+	assert.True(t, request.authorization)
+}
+
+func TestOpensearchFailover(t *testing.T) {
+	host, port, requests := startOpensearchStub(t, nil)
+	// nothing listens on the closed port, so connection to the first node is refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	nodes := ParseNodes(fmt.Sprintf(" https://%s/, https://%s ,", closedAddress, net.JoinHostPort(host, strconv.Itoa(port))))
+	assert.Len(t, nodes, 2)
+	opensearch := NewOpensearch(context.Background(), Config{
+		Host:         "opensearch",
+		Port:         9200,
+		Protocol:     common.Https,
+		Username:     "opensearch",
+		Password:     "change",
+		Nodes:        nodes,
+		MaxRetries:   3,
+		RetryBackoff: time.Millisecond,
+	})
+	assert.Equal(t, common.Up, opensearch.Health.Status)
+	assert.Len(t, requests, 1)
+}
+
+func TestOpensearchRetryOnServerError(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"version":{"number":"2.11.0","distribution":"opensearch"}}`))
+			return
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"status":"yellow"}]`))
+	}))
+	defer server.Close()
+
+	opensearch := NewOpensearch(context.Background(), Config{
+		Protocol:     common.Http,
+		Nodes:        []string{server.URL},
+		MaxRetries:   3,
+		RetryBackoff: time.Millisecond,
+	})
+	assert.Equal(t, "WARNING", opensearch.Health.Status)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestOpensearchRetryOnlyIdempotentRequests(t *testing.T) {
+	attempts := make(map[string]int)
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"version":{"number":"2.11.0","distribution":"opensearch"}}`))
+			return
+		}
+		if r.URL.Path == "/_cat/health" {
+			_, _ = w.Write([]byte(`[{"status":"green"}]`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mutex.Lock()
+		attempts[r.Method+" "+string(body)]++
+		mutex.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	opensearch := NewOpensearch(context.Background(), Config{
+		Protocol:     common.Http,
+		Nodes:        []string{server.URL},
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		request, err := http.NewRequest(method, "/orders/_doc/1", strings.NewReader(`{"id":1}`))
+		require.NoError(t, err)
+		response, err := opensearch.Client.Perform(request)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+		_ = response.Body.Close()
+	}
+	assert.Equal(t, 1, attempts[http.MethodPost+` {"id":1}`])
+	assert.Equal(t, 3, attempts[http.MethodPut+` {"id":1}`])
+}
+
+func TestShouldRetryConnectionErrors(t *testing.T) {
+	post, err := http.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(`{"index":{"_index":"orders"}}`))
+	require.NoError(t, err)
+	put, err := http.NewRequest(http.MethodPut, "/orders/_doc/1", strings.NewReader(`{"id":1}`))
+	require.NoError(t, err)
+	eof := &url.Error{Op: "Post", URL: "http://opensearch:9200/_bulk", Err: io.EOF}
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	refused := &url.Error{Op: "Post", URL: "http://opensearch:9200/_bulk",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+
+	// the request could be already applied by OpenSearch, so only idempotent one is retried
+	assert.False(t, shouldRetry(post, nil, eof))
+	assert.False(t, shouldRetry(post, nil, reset))
+	assert.True(t, shouldRetry(put, nil, eof))
+	assert.True(t, shouldRetry(put, nil, reset))
+	// the request is not sent
+	assert.True(t, shouldRetry(post, nil, refused))
+	assert.True(t, shouldRetry(put, nil, refused))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	config := Config{RetryBackoff: 100 * time.Millisecond}
+	assert.Equal(t, 100*time.Millisecond, config.retryBackoff(1))
+	assert.Equal(t, 400*time.Millisecond, config.retryBackoff(3))
+	assert.Equal(t, maxRetryBackoff, config.retryBackoff(20))
+}
@@ -23,14 +23,11 @@ import (
 	"github.com/Netcracker/dbaas-opensearch-adapter/server"
 	"log"
 	"os"
-	"strconv"
 	"strings"
 )
 
 var (
-	tlsEnabled, _   = strconv.ParseBool(common.GetEnv("TLS_ENABLED", "false"))
-	adapterPort     = 8080
-	adapterProtocol = common.Http
+	serverConfig    = server.NewConfig()
 	adapterUsername = common.GetEnv("DBAAS_ADAPTER_USERNAME", "dbaas-aggregator")
 	adapterPassword = common.GetEnv("DBAAS_ADAPTER_PASSWORD", "dbaas-aggregator")
 	adapterAddress  = common.GetEnv("DBAAS_ADAPTER_ADDRESS", "")
@@ -46,9 +43,12 @@ var (
 
 func main() {
 	logger.Info(fmt.Sprintf("Run build %s / %s with %+v ...", buildstamp, githash, os.Args))
+	serverConfig.RegisterFlags(flag.CommandLine)
 	flag.Parse()
-	if tlsEnabled {
-		adapterPort = 8443
+	adapterPort := serverConfig.HttpPort
+	adapterProtocol := common.Http
+	if !serverConfig.HttpEnabled {
+		adapterPort = serverConfig.HttpsPort
 		adapterProtocol = common.Https
 	}
 	cl := client.NewAdapterClient(adapterProtocol, "", adapterPort, adapterUsername, adapterPassword)
@@ -66,7 +66,10 @@ func main() {
 		return
 	}
 
-	log.Fatalln("Fatal error", server.Server(adapterAddress, adapterUsername, adapterPassword))
+	if err := server.Server(serverConfig, adapterAddress, adapterUsername, adapterPassword); err != nil {
+		log.Fatalln("Fatal error", err)
+	}
+	logger.Info("Adapter is stopped")
 }
 
 func terminal(reader *bufio.Reader, cl *client.AdapterClient) {
@@ -14,14 +14,19 @@
 
 package common
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 // BackgroundExecutor runs tasks in a single goroutine. Tasks are submitted in queue and being executed according
 // to the FIFO rule. Always use constructor NewBackgroundExecutor() to create new instance of the BackgroundExecutor.
 // BackgroundExecutor can be shutdown by calling Shutdown() function.
 type BackgroundExecutor struct {
 	// queue contains submitted tasks that need to be run
-	queue  chan func()
+	queue chan func()
+	// done is closed when all the submitted tasks are finished after shutdown
+	done   chan struct{}
 	active bool
 	mutex  sync.Mutex
 }
@@ -30,6 +35,7 @@ type BackgroundExecutor struct {
 func NewBackgroundExecutor() *BackgroundExecutor {
 	executor := BackgroundExecutor{
 		queue:  make(chan func(), 5),
+		done:   make(chan struct{}),
 		active: true,
 	}
 	executor.start()
@@ -55,9 +61,20 @@ func (executor *BackgroundExecutor) Shutdown() {
 	}
 }
 
+// AwaitTermination blocks until all the submitted works are finished after Shutdown() or the context is done.
+func (executor *BackgroundExecutor) AwaitTermination(ctx context.Context) error {
+	select {
+	case <-executor.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Starts taking work from queue and running it until the task channel is closed.
 func (executor *BackgroundExecutor) start() {
 	go func() {
+		defer close(executor.done)
 		for {
 			work, more := <-executor.queue
 			if more {
@@ -41,6 +41,7 @@ const (
 	ResourcePrefixKind = "resourcePrefix"
 	TemplateKind       = "template"
 	IndexTemplateKind  = "indexTemplate"
+	RoleKind           = "role"
 	UserKind           = "user"
 	Down               = "DOWN"
 	OutOfService       = "OUT_OF_SERVICE"
@@ -69,15 +70,16 @@ type ComponentHealth struct {
 }
 
 type ConnectionProperties struct {
-	DbName         string `json:"dbName"`
-	Host           string `json:"host"`
-	Port           int    `json:"port"`
-	Url            string `json:"url"`
-	Username       string `json:"username,omitempty"`
-	Password       string `json:"password,omitempty"`
-	ResourcePrefix string `json:"resourcePrefix,omitempty"`
-	Role           string `json:"role,omitempty"`
-	Tls            bool   `json:"tls,omitempty"`
+	DbName         string   `json:"dbName"`
+	Host           string   `json:"host"`
+	Port           int      `json:"port"`
+	Url            string   `json:"url"`
+	Username       string   `json:"username,omitempty"`
+	Password       string   `json:"password,omitempty"`
+	ResourcePrefix string   `json:"resourcePrefix,omitempty"`
+	Role           string   `json:"role,omitempty"`
+	Tls            bool     `json:"tls,omitempty"`
+	Nodes          []string `json:"nodes,omitempty"`
 }
 
 type Supports struct {
@@ -231,11 +233,13 @@ func CheckPrefixUniqueness(prefix string, ctx context.Context, opensearchcli Cli
 		for element, user := range users {
 			if strings.HasPrefix(element, prefix) {
 				logger.ErrorContext(ctx, fmt.Sprintf("provided prefix already exists or a part of another prefix: %+v", prefix))
-				return false, fmt.Errorf("provided prefix already exists or a part of another prefix: %+v", prefix)
+				return false, NewConflictError(PrefixConflictCode,
+					fmt.Errorf("provided prefix already exists or a part of another prefix: %+v", prefix))
 			}
 			if user.Attributes[resourcePrefixAttributeName] != "" && strings.HasPrefix(user.Attributes[resourcePrefixAttributeName], prefix) {
 				logger.ErrorContext(ctx, fmt.Sprintf("provided prefix already exists or a part of another prefix: %+v", prefix))
-				return false, fmt.Errorf("provided prefix already exists or a part of another prefix: %+v", prefix)
+				return false, NewConflictError(PrefixConflictCode,
+					fmt.Errorf("provided prefix already exists or a part of another prefix: %+v", prefix))
 			}
 		}
 	} else if response.StatusCode == http.StatusNotFound {
@@ -0,0 +1,106 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	InternalErrorCode        = "INTERNAL_ERROR"
+	InvalidRequestBodyCode   = "INVALID_REQUEST_BODY"
+	InvalidRequestCode       = "INVALID_REQUEST"
+	ValidationErrorCode      = "VALIDATION_FAILED"
+	InvalidPrefixCode        = "INVALID_PREFIX"
+	UnauthorizedCode         = "UNAUTHORIZED"
+	PrefixConflictCode       = "PREFIX_CONFLICT"
+	NotFoundCode             = "NOT_FOUND"
+	BackupNotFoundCode       = "BACKUP_NOT_FOUND"
+	CuratorErrorCode         = "CURATOR_ERROR"
+	ShuttingDownCode         = "SHUTTING_DOWN"
+	InvalidPasswordCode      = "INVALID_PASSWORD"
+	RecoveryNotResumableCode = "RECOVERY_NOT_RESUMABLE"
+)
+
+// Error is an error returned by REST API of the adapter as JSON body with the corresponding HTTP status.
+type Error struct {
+	Details   interface{} `json:"details,omitempty"`
+	cause     error
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestId string `json:"requestId,omitempty"`
+	Status    int    `json:"status"`
+}
+
+func NewError(status int, code string, err error) *Error {
+	return &Error{
+		cause:   err,
+		Code:    code,
+		Message: err.Error(),
+		Status:  status,
+	}
+}
+
+func NewBadRequestError(code string, err error) *Error {
+	return NewError(http.StatusBadRequest, code, err)
+}
+
+func NewNotFoundError(code string, err error) *Error {
+	return NewError(http.StatusNotFound, code, err)
+}
+
+func NewConflictError(code string, err error) *Error {
+	return NewError(http.StatusConflict, code, err)
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// WithDetails returns a copy of the error with additional information about the failure.
+func (e *Error) WithDetails(details interface{}) *Error {
+	withDetails := *e
+	withDetails.Details = details
+	return &withDetails
+}
+
+// WriteError writes the error to the response in JSON format.
+// Errors which are not wrapped into Error are considered as internal ones and are returned with 500 status.
+func WriteError(w http.ResponseWriter, err error, ctx context.Context) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = NewError(http.StatusInternalServerError, InternalErrorCode, err)
+	}
+	response := *apiErr
+	if requestId, ok := ctx.Value(RequestIdKey).(string); ok {
+		response.RequestId = requestId
+	}
+	responseBody, marshalErr := json.Marshal(response)
+	if marshalErr != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("Failed to serialize error response: %v", marshalErr))
+		responseBody = []byte(fmt.Sprintf(`{"status":%d,"code":%q,"message":%q}`,
+			response.Status, response.Code, response.Message))
+	}
+	w.WriteHeader(response.Status)
+	_, _ = w.Write(responseBody)
+}
@@ -0,0 +1,52 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteError(t *testing.T) {
+	ctx := context.WithValue(context.Background(), RequestIdKey, "test-request-id")
+	err := fmt.Errorf("wrapped: %w", NewBadRequestError(InvalidPrefixCode, errors.New("prefix contains forbidden symbols")))
+	recorder := httptest.NewRecorder()
+	WriteError(recorder, err, ctx)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	var response Error
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusBadRequest, response.Status)
+	assert.Equal(t, InvalidPrefixCode, response.Code)
+	assert.Equal(t, "prefix contains forbidden symbols", response.Message)
+	assert.Equal(t, "test-request-id", response.RequestId)
+}
+
+func TestWriteInternalError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	WriteError(recorder, errors.New("connection refused"), context.Background())
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	var response Error
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, InternalErrorCode, response.Code)
+	assert.Equal(t, "connection refused", response.Message)
+	assert.Empty(t, response.RequestId)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/opensearch-project/opensearch-go/opensearchtransport"
 	"io"
 	"net/http"
+	"path"
 	"strings"
 )
 
@@ -29,6 +30,9 @@ type Client interface {
 	DiscoverNodes() error
 }
 
+// FailingUsername is a prefix of user names which are absent in the stub and cannot be created
+const FailingUsername = "failinguser"
+
 type ClientStub struct {
 }
 
@@ -44,9 +48,28 @@ func (cs *ClientStub) Perform(req *http.Request) (*http.Response, error) {
 	statusCode := http.StatusOK
 	body := ""
 	switch {
+	case strings.HasPrefix(path, "/dbaas_opensearch_metadata/_search"):
+		body = `{"took":1,"timed_out":false,"hits":{"total":{"value":2,"relation":"eq"},"hits":[{"_index":"dbaas_opensearch_metadata","_id":"stubprefix","_source":{"classifier":{"microserviceName":"stub-service","namespace":"stub-namespace"},"microserviceName":"stub-service"}},{"_index":"dbaas_opensearch_metadata","_id":"testme","_source":{"text":"check"}}]}}`
+	case strings.HasPrefix(path, "/dbaas_opensearch_recovery_jobs/_search"):
+		body = `{"hits":{"hits":[{"_id":"stubjob","_source":{"id":"stubjob","state":"running","startedAt":"2025-01-01T00:00:00Z","totalUsers":200,"totalBatches":2,"completedBatches":1,"restoredUsers":100,"failedUsers":0}}]}}`
+	case strings.HasPrefix(path, "/dbaas_opensearch_recovery_jobs/_doc/"):
+		id := strings.TrimPrefix(path, "/dbaas_opensearch_recovery_jobs/_doc/")
+		body = fmt.Sprintf(`{"_index":"dbaas_opensearch_recovery_jobs","_id":"%s","_version":1,"result":"created"}`, id)
+	case strings.HasPrefix(path, "/dbaas_opensearch_tombstones/_search"):
+		body = `{"hits":{"hits":[{"_id":"expiredprefix","_source":{"name":"expiredprefix","deletedAt":"2000-01-01T00:00:00Z","expiresAt":"2000-01-02T00:00:00Z","resources":[{"kind":"user","name":"expiredprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1"},{"kind":"index","name":"expiredprefix_orders"}]}},{"_id":"deletedprefix","_source":{"name":"deletedprefix","deletedAt":"2999-01-01T00:00:00Z","expiresAt":"2999-01-02T00:00:00Z","resources":[{"kind":"index","name":"deletedprefix_orders"}]}}]}}`
+	case strings.HasPrefix(path, "/dbaas_opensearch_tombstones/_doc/"):
+		id := strings.TrimPrefix(path, "/dbaas_opensearch_tombstones/_doc/")
+		body, statusCode = cs.tombstoneManipulations(id, method)
+	case strings.Contains(path, "/_stats"):
+		patterns := strings.Split(strings.TrimPrefix(path, "/"), "/")[0]
+		body = cs.indicesStats(strings.Split(patterns, ","))
+	case strings.HasSuffix(path, "/_close") || strings.HasSuffix(path, "/_open"):
+		body = `{"acknowledged":true,"shards_acknowledged":true}`
 	case strings.HasPrefix(path, "/dbaas_opensearch_metadata/_doc"):
 		index := strings.ReplaceAll(path, "/dbaas_opensearch_metadata/_doc", "")
 		body = cs.metadataManipulations(index, method)
+	case path == "/_plugins/_security/api/roles":
+		body = `{"dbaas_admin_role":{"reserved":false,"hidden":false,"cluster_permissions":["cluster_composite_ops"],"index_permissions":[],"static":false},"dbaas_db_stubprefix_admin":{"reserved":false,"hidden":false,"description":"Additional permissions of 'stubprefix' database","cluster_permissions":[],"index_permissions":[{"index_patterns":["stubprefix_*"],"allowed_actions":["indices:admin/resize"]}],"static":false},"dbaas_db_stubprefix2_admin":{"reserved":false,"hidden":false,"description":"Additional permissions of 'stubprefix2' database","cluster_permissions":["cluster:admin/snapshot/get"],"index_permissions":[],"static":false}}`
 	case strings.HasPrefix(path, "/_plugins/_security/api/roles/"):
 		role := strings.ReplaceAll(path, "/_plugins/_security/api/roles/", "")
 		body = cs.roleManipulations(role, method)
@@ -54,13 +77,38 @@ func (cs *ClientStub) Perform(req *http.Request) (*http.Response, error) {
 		role := strings.ReplaceAll(path, "/_plugins/_security/api/rolesmapping", "")
 		body = cs.roleMappingManipulations(role, method)
 	case strings.HasPrefix(path, "/_plugins/_security/api/internalusers"):
-		username := strings.ReplaceAll(path, "/_plugins/_security/api/internalusers/", "")
+		username := strings.TrimPrefix(strings.TrimPrefix(path, "/_plugins/_security/api/internalusers"), "/")
+		if username == "" && method == http.MethodPatch && req.Body != nil {
+			// batches with failing users are rejected as a whole
+			content, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			if strings.Contains(string(content), "/"+FailingUsername) {
+				statusCode = http.StatusBadRequest
+				body = `{"status":"error","message":"Invalid user content"}`
+				break
+			}
+		}
+		if strings.HasPrefix(username, FailingUsername) {
+			if method == http.MethodPut {
+				return nil, fmt.Errorf("unable to create '%s' user", username)
+			}
+			statusCode = http.StatusNotFound
+			body = fmt.Sprintf(`{"status":"NOT_FOUND","message":"'%s' not found."}`, username)
+			break
+		}
 		body = cs.userManipulations(username, method)
+	case strings.HasPrefix(path, "/_template/"):
+		template := strings.ReplaceAll(path, "/_template/", "")
+		body = cs.legacyTemplateManipulations(template, method)
 	case strings.HasPrefix(path, "/_index_template/"):
 		template := strings.ReplaceAll(path, "/_index_template/", "")
 		body = cs.templateManipulations(template, method)
 	case strings.HasPrefix(path, "/_nodes/reload_secure_settings"):
 		body = `{"_nodes":{"total":3,"successful":3,"failed":0},"cluster_name":"opensearch","nodes":{"ddfIN7-sT3avYl4DFZfKeg":{"name":"opensearch-1"},"jxL6tjiZTIiSjxmh6wTGvw":{"name":"opensearch-0"},"jxL6tjKlshIiSjLmh6wTGvw":{"name":"opensearch-2"}}}`
+	case strings.HasPrefix(path, "/_cluster/settings"):
+		body = `{"persistent":{},"transient":{},"defaults":{"plugins.security.restapi.password_validation_regex":"(?=.*[A-Z])(?=.*[^a-zA-Z\\d])(?=.*[0-9])(?=.*[a-z]).{8,}"}}`
 	case strings.HasPrefix(path, "/_alias/"):
 		alias := strings.ReplaceAll(path, "/_alias/", "")
 		body = cs.aliasManipulations(alias, method)
@@ -69,6 +117,9 @@ func (cs *ClientStub) Perform(req *http.Request) (*http.Response, error) {
 		body = cs.aliasManipulations(alias, method)
 	case strings.Contains(path, "/_snapshot/snapshots/_verify"):
 		body = "{\"status\": 200}"
+	case strings.HasPrefix(path, "/_cat/indices") && req.URL.Query().Get("format") == "json":
+		pattern := strings.TrimPrefix(strings.TrimPrefix(path, "/_cat/indices"), "/")
+		body = cs.catIndices(pattern)
 	case strings.HasPrefix(path, "/_cat/indices"):
 		body = `dbaas_metadata
 dbaas_opensearch_metadata
@@ -111,6 +162,8 @@ func (cs *ClientStub) roleManipulations(name string, method string) string {
 	switch method {
 	case http.MethodGet:
 		switch {
+		case strings.HasPrefix(name, "dbaas_db_stubprefix_"):
+			return fmt.Sprintf(`{"%s":{"reserved":false,"hidden":false,"description":"Additional permissions of 'stubprefix' database","cluster_permissions":[],"index_permissions":[{"index_patterns":["stubprefix_*"],"allowed_actions":["indices:admin/resize"]}],"static":false}}`, name)
 		case strings.Contains(name, "dml"):
 			return `{"dbaas_dml_role":{"reserved":false,"hidden":false,"cluster_permissions":["cluster_composite_ops","CLUSTER_COMPOSITE_OPS","cluster:monitor/state"],"index_permissions":[{"index_patterns":["${attr.internal.resource_prefix}*"],"fls":[],"masked_fields":[],"allowed_actions":["indices:data/*","INDICES:DATA/*", "indices:admin/mapping/put"]}],"tenant_permissions":[],"static":false}}`
 		case strings.Contains(name, "readonly"):
@@ -147,6 +200,8 @@ func (cs *ClientStub) roleMappingManipulations(name string, method string) strin
 		}
 	case http.MethodPut:
 		return fmt.Sprintf(`{"status":"OK","message":"'%s' updated."}`, name)
+	case http.MethodDelete:
+		return fmt.Sprintf(`{"status":"OK","message":"'%s' deleted."}`, name)
 	default:
 		logger.Error(fmt.Sprintf("Role operations do not include '%s' method", method))
 		return ""
@@ -156,6 +211,9 @@ func (cs *ClientStub) roleMappingManipulations(name string, method string) strin
 func (cs *ClientStub) userManipulations(name string, method string) string {
 	switch method {
 	case http.MethodGet:
+		if name == "" {
+			return `{"admin":{"hash":"","reserved":true,"hidden":false,"backend_roles":["admin"],"attributes":{},"opendistro_security_roles":[],"static":false},"stubprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1":{"hash":"","reserved":false,"hidden":false,"backend_roles":["dbaas_admin"],"attributes":{"resource_prefix":"stubprefix"},"opendistro_security_roles":[],"static":false},"stubprefix_9f1e7c3a2b4d4f6e8a0c5b7d9e1f3a5c":{"hash":"","reserved":false,"hidden":false,"backend_roles":["dbaas_readonly"],"attributes":{"resource_prefix":"stubprefix"},"opendistro_security_roles":[],"static":false},"stubprefix_b2d4f6a8c0e24a6c8e0a2c4e6a8c0e2a":{"hash":"","reserved":false,"hidden":false,"backend_roles":["dbaas_dml"],"attributes":{"resource_prefix":"stubprefix"},"opendistro_security_roles":[],"static":false},"stubprefix_c3e5a7c9e1f34b5d7f9b1d3f5b7d9f1b":{"hash":"","reserved":false,"hidden":false,"backend_roles":["dbaas_ism"],"attributes":{"resource_prefix":"stubprefix"},"opendistro_security_roles":[],"static":false}}`
+		}
 		if strings.HasPrefix(name, "dbaas_") {
 			return fmt.Sprintf(`{"%s":{"hash":"","reserved":false,"hidden":false,"backend_roles":["%s"],"attributes":{},"opendistro_security_roles":[],"static":false}}`, name, name)
 		}
@@ -176,6 +234,9 @@ func (cs *ClientStub) userManipulations(name string, method string) string {
 func (cs *ClientStub) templateManipulations(name string, method string) string {
 	switch method {
 	case http.MethodGet:
+		if strings.HasSuffix(name, "*") {
+			name = fmt.Sprintf("%s_index_template", trimPattern(name))
+		}
 		return fmt.Sprintf(`{"index_templates":[{"name":"%s","index_template":{"index_patterns":["test*"],"template":{"settings":{"index":{"number_of_shards":"3","number_of_replicas":"1"}}},"composed_of":[]}}]}`, name)
 	case http.MethodDelete:
 		return `{"acknowledged":true}`
@@ -185,10 +246,58 @@ func (cs *ClientStub) templateManipulations(name string, method string) string {
 	}
 }
 
+func (cs *ClientStub) legacyTemplateManipulations(name string, method string) string {
+	switch method {
+	case http.MethodGet:
+		template := name
+		if strings.HasSuffix(name, "*") {
+			template = fmt.Sprintf("%s_template", trimPattern(name))
+		}
+		return fmt.Sprintf(`{"%s":{"order":0,"index_patterns":["%s*"],"settings":{},"mappings":{},"aliases":{}}}`, template, trimPattern(name))
+	case http.MethodDelete:
+		return `{"acknowledged":true}`
+	default:
+		logger.Error(fmt.Sprintf("Template operations do not include '%s' method", method))
+		return ""
+	}
+}
+
+func (cs *ClientStub) catIndices(pattern string) string {
+	if pattern == "" {
+		return `[{"health":"green","status":"open","index":"dbaas_opensearch_metadata","pri":"1","rep":"1","docs.count":"12","store.size":"40960","pri.store.size":"20480"},{"health":"green","status":"open","index":".opendistro_security","pri":"1","rep":"1","docs.count":"10","store.size":"81920","pri.store.size":"40960"},{"health":"green","status":"open","index":"stubprefix_orders","pri":"3","rep":"1","docs.count":"100","store.size":"204800","pri.store.size":"102400"},{"health":"green","status":"open","index":"stubprefix_customers","pri":"1","rep":"1","docs.count":"20","store.size":"10240","pri.store.size":"5120"},{"health":"green","status":"open","index":"testme","pri":"1","rep":"1","docs.count":"1","store.size":"1024","pri.store.size":"512"},{"health":"green","status":"open","index":"stubprefix2_orders","pri":"1","rep":"1","docs.count":"5","store.size":"2048","pri.store.size":"1024"}]`
+	}
+	// `prefix2_orders` index collides with the prefix and is returned only if the pattern matches it
+	prefix := trimPattern(pattern)
+	indices := []string{
+		fmt.Sprintf(`{"health":"green","status":"open","index":"%s_orders","pri":"3","rep":"1","docs.count":"100","store.size":"204800","pri.store.size":"102400"}`, prefix),
+		fmt.Sprintf(`{"health":"green","status":"open","index":"%s_customers","pri":"1","rep":"1","docs.count":"20","store.size":"10240","pri.store.size":"5120"}`, prefix),
+	}
+	if matched, _ := path.Match(pattern, prefix+"2_orders"); matched {
+		indices = append(indices, fmt.Sprintf(`{"health":"green","status":"open","index":"%s2_orders","pri":"1","rep":"1","docs.count":"5","store.size":"2048","pri.store.size":"1024"}`, prefix))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(indices, ","))
+}
+
+// indicesStats returns `orders` and `customers` indices for each `prefix_*` pattern
+func (cs *ClientStub) indicesStats(patterns []string) string {
+	var indices []string
+	for _, pattern := range patterns {
+		prefix := trimPattern(pattern)
+		indices = append(indices,
+			fmt.Sprintf(`"%s_orders":{"primaries":{"docs":{"count":100,"deleted":2},"store":{"size_in_bytes":102400},"indexing":{"index_total":120},"search":{"query_total":40},"segments":{"count":3}},"total":{"docs":{"count":200,"deleted":4},"store":{"size_in_bytes":204800},"indexing":{"index_total":240},"search":{"query_total":80},"segments":{"count":6}}}`, prefix),
+			fmt.Sprintf(`"%s_customers":{"primaries":{"docs":{"count":20,"deleted":0},"store":{"size_in_bytes":5120},"indexing":{"index_total":20},"search":{"query_total":10},"segments":{"count":1}},"total":{"docs":{"count":40,"deleted":0},"store":{"size_in_bytes":10240},"indexing":{"index_total":40},"search":{"query_total":20},"segments":{"count":2}}}`, prefix))
+	}
+	return fmt.Sprintf(`{"_shards":{"total":8,"successful":8,"failed":0},"indices":{%s}}`, strings.Join(indices, ","))
+}
+
 func (cs *ClientStub) aliasManipulations(name string, method string) string {
 	logger.Info(fmt.Sprintf("Name is %s, method is %s", name, method))
 	switch method {
 	case http.MethodGet:
+		if strings.HasSuffix(name, "*") {
+			prefix := trimPattern(name)
+			return fmt.Sprintf(`{"%s_orders":{"aliases":{"%s_alias":{}}}}`, prefix, prefix)
+		}
 		return fmt.Sprintf(`{"test-news":{"aliases":{"%s":{}}}}`, name)
 	case http.MethodDelete:
 		return `{"acknowledged":true}`
@@ -198,6 +307,27 @@ func (cs *ClientStub) aliasManipulations(name string, method string) string {
 	}
 }
 
+// tombstoneManipulations knows soft deleted 'deletedprefix' database and 'expiredprefix' one whose retention is over,
+// tombstone of 'unavailableprefix' cannot be read because shards of the index are unavailable
+func (cs *ClientStub) tombstoneManipulations(id string, method string) (string, int) {
+	switch method {
+	case http.MethodGet:
+		switch id {
+		case "deletedprefix":
+			return `{"found":true,"_source":{"name":"deletedprefix","deletedAt":"2999-01-01T00:00:00Z","expiresAt":"2999-01-02T00:00:00Z","metadata":{"deletedprefix":{"classifier":{"microserviceName":"stub-service","namespace":"stub-namespace"}}},"resources":[{"kind":"user","name":"deletedprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1"},{"kind":"index","name":"deletedprefix_orders"},{"kind":"alias","name":"deletedprefix_alias"}],"userRoles":{"deletedprefix_4a2cd8f9b0e54e0c9d5e1f27a8c3b6d1":["deletedprefix_admin"]}}}`, http.StatusOK
+		case "expiredprefix":
+			return `{"found":true,"_source":{"name":"expiredprefix","deletedAt":"2000-01-01T00:00:00Z","expiresAt":"2000-01-02T00:00:00Z","resources":[{"kind":"index","name":"expiredprefix_orders"}]}}`, http.StatusOK
+		case "unavailableprefix":
+			return `{"error":{"type":"no_shard_available_action_exception","reason":"No shard available"},"status":503}`, http.StatusServiceUnavailable
+		}
+		return fmt.Sprintf(`{"_index":"dbaas_opensearch_tombstones","_id":"%s","found":false}`, id), http.StatusNotFound
+	case http.MethodDelete:
+		return fmt.Sprintf(`{"_index":"dbaas_opensearch_tombstones","_id":"%s","result":"deleted"}`, id), http.StatusOK
+	default:
+		return fmt.Sprintf(`{"_index":"dbaas_opensearch_tombstones","_id":"%s","_version":1,"result":"created"}`, id), http.StatusOK
+	}
+}
+
 func (cs *ClientStub) indexManipulations(name string, method string) string {
 	switch method {
 	case http.MethodGet:
@@ -212,6 +342,11 @@ func (cs *ClientStub) indexManipulations(name string, method string) string {
 	}
 }
 
+// trimPattern returns the prefix of `prefix*` or `prefix_*` pattern
+func trimPattern(pattern string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(pattern, "*"), "_")
+}
+
 func (cs *ClientStub) Metrics() (opensearchtransport.Metrics, error) {
 	return opensearchtransport.Metrics{}, nil
 }
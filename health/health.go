@@ -56,3 +56,38 @@ func (h *Health) DetermineHealthStatus(ctx context.Context) {
 		}
 	}
 }
+
+// ClustersHealth is health of the adapter which serves several OpenSearch clusters, its status is the worst status of the clusters
+type ClustersHealth struct {
+	Status   string             `json:"status"`
+	Clusters map[string]*Health `json:"clusters"`
+}
+
+func (ch *ClustersHealth) HealthHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.PrepareContext(r)
+		ch.DetermineHealthStatus(ctx)
+		responseBody, err := json.Marshal(ch)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			errorMessage := fmt.Sprintf("Error occurred during health serialization: %s", err.Error())
+			_, _ = w.Write([]byte(errorMessage))
+			return
+		}
+		_, _ = w.Write(responseBody)
+	}
+}
+
+func (ch *ClustersHealth) DetermineHealthStatus(ctx context.Context) {
+	for _, clusterHealth := range ch.Clusters {
+		clusterHealth.DetermineHealthStatus(ctx)
+	}
+	for _, status := range healthStatuses {
+		for _, clusterHealth := range ch.Clusters {
+			if status == clusterHealth.Status {
+				ch.Status = status
+				return
+			}
+		}
+	}
+}
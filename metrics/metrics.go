@@ -0,0 +1,254 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/gorilla/mux"
+	"github.com/opensearch-project/opensearch-go/opensearchtransport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "dbaas_opensearch_adapter"
+
+	SuccessOutcome = "success"
+	FailureOutcome = "failure"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests processed by the adapter.",
+	}, []string{"route", "method", "code"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests processed by the adapter.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	opensearchRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "opensearch_requests_total",
+		Help:      "Number of requests sent to OpenSearch.",
+	}, []string{"cluster", "api", "method"})
+	opensearchErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "opensearch_errors_total",
+		Help:      "Number of requests to OpenSearch which failed or returned 5xx status.",
+	}, []string{"cluster", "api", "method"})
+	opensearchRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "opensearch_request_duration_seconds",
+		Help:      "Duration of requests sent to OpenSearch.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"cluster", "api", "method"})
+
+	curatorRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "curator_requests_total",
+		Help:      "Number of requests sent to the backup daemon (curator) by outcome.",
+	}, []string{"cluster", "operation", "outcome"})
+
+	registrationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Number of physical database registration attempts in DBaaS aggregator by outcome.",
+	}, []string{"cluster", "outcome"})
+
+	tlsReloadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tls_reloads_total",
+		Help:      "Number of successful reloads of TLS certificates.",
+	}, []string{"cluster", "name"})
+
+	usersRecoveryState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "users_recovery_state",
+		Help:      "Current state of users recovery, the gauge of the current state is 1, others are 0.",
+	}, []string{"cluster", "state"})
+
+	roleDrift = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "role_drift",
+		Help:      "Whether the role or role mapping managed by the adapter differed from desired state on the latest check.",
+	}, []string{"cluster", "kind", "name"})
+	roleDriftsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "role_drifts_total",
+		Help:      "Number of detected differences of roles and role mappings managed by the adapter from desired state.",
+	}, []string{"cluster", "kind", "name"})
+)
+
+// Handler returns HTTP handler which exposes all registered metrics in Prometheus format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware collects request count and latency per route template of mux router
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if currentRoute := mux.CurrentRoute(r); currentRoute != nil {
+			if template, err := currentRoute.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+		httpRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		httpRequestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// InstrumentedClient is OpenSearch client which collects latency and errors of performed requests
+type InstrumentedClient struct {
+	common.Client
+	// cluster is the identifier of OpenSearch cluster in metrics
+	cluster string
+}
+
+func NewInstrumentedClient(cluster string, client common.Client) *InstrumentedClient {
+	return &InstrumentedClient{Client: client, cluster: cluster}
+}
+
+func (ic *InstrumentedClient) Perform(req *http.Request) (*http.Response, error) {
+	api := apiName(req.URL.Path)
+	start := time.Now()
+	response, err := ic.Client.Perform(req)
+	opensearchRequestDuration.WithLabelValues(ic.cluster, api, req.Method).Observe(time.Since(start).Seconds())
+	opensearchRequestsTotal.WithLabelValues(ic.cluster, api, req.Method).Inc()
+	if err != nil || response.StatusCode >= http.StatusInternalServerError {
+		opensearchErrorsTotal.WithLabelValues(ic.cluster, api, req.Method).Inc()
+	}
+	return response, err
+}
+
+func (ic *InstrumentedClient) Metrics() (opensearchtransport.Metrics, error) {
+	return ic.Client.Metrics()
+}
+
+func (ic *InstrumentedClient) DiscoverNodes() error {
+	return ic.Client.DiscoverNodes()
+}
+
+// apiName returns the name of OpenSearch API by request path, index names are omitted to keep the number of labels limited
+func apiName(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "_") {
+			continue
+		}
+		switch segment {
+		case "_plugins":
+			// Security plugin API, for example `/_plugins/_security/api/internalusers/{name}`
+			if len(segments) > i+3 {
+				return strings.Join(segments[i:i+4], "/")
+			}
+			return strings.Join(segments[i:], "/")
+		case "_cat", "_nodes", "_snapshot":
+			if len(segments) > i+1 && strings.HasPrefix(segments[i+1], "_") {
+				return strings.Join(segments[i:i+2], "/")
+			}
+			return segment
+		default:
+			return segment
+		}
+	}
+	if segments[0] == "" {
+		return "root"
+	}
+	return "index"
+}
+
+// CuratorTransport is HTTP transport which counts outcomes of requests sent to curator of the cluster
+type CuratorTransport struct {
+	Transport http.RoundTripper
+	Cluster   string
+}
+
+func (ct *CuratorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := ct.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	operation := strings.SplitN(strings.Trim(req.URL.Path, "/"), "/", 2)[0]
+	response, err := transport.RoundTrip(req)
+	outcome := SuccessOutcome
+	if err != nil || response.StatusCode >= http.StatusBadRequest {
+		outcome = FailureOutcome
+	}
+	curatorRequestsTotal.WithLabelValues(ct.Cluster, operation, outcome).Inc()
+	return response, err
+}
+
+// InstrumentCuratorClient returns a copy of curator client of the cluster which counts outcomes of performed requests
+func InstrumentCuratorClient(cluster string, client *http.Client) *http.Client {
+	if client == nil {
+		return nil
+	}
+	instrumented := *client
+	instrumented.Transport = &CuratorTransport{Transport: client.Transport, Cluster: cluster}
+	return &instrumented
+}
+
+// ObserveRegistration counts physical database registration attempt of the cluster with the given outcome
+func ObserveRegistration(cluster string, outcome string) {
+	registrationsTotal.WithLabelValues(cluster, outcome).Inc()
+}
+
+// ObserveTLSReload counts successful reload of TLS certificates with the given name, cluster is empty for certificates
+// shared by all clusters
+func ObserveTLSReload(cluster string, name string) {
+	tlsReloadsTotal.WithLabelValues(cluster, name).Inc()
+}
+
+// SetUsersRecoveryState sets gauge of the given state of the cluster to 1 and gauges of other known states to 0
+func SetUsersRecoveryState(cluster string, state string, states ...string) {
+	for _, s := range states {
+		usersRecoveryState.WithLabelValues(cluster, s).Set(0)
+	}
+	usersRecoveryState.WithLabelValues(cluster, state).Set(1)
+}
+
+// ObserveRoleDrift sets drift gauge of the role or role mapping of the cluster and counts detected drift
+func ObserveRoleDrift(cluster string, kind string, name string, drifted bool) {
+	if !drifted {
+		roleDrift.WithLabelValues(cluster, kind, name).Set(0)
+		return
+	}
+	roleDrift.WithLabelValues(cluster, kind, name).Set(1)
+	roleDriftsTotal.WithLabelValues(cluster, kind, name).Inc()
+}
@@ -0,0 +1,86 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiName(t *testing.T) {
+	paths := map[string]string{
+		"/":                                           "root",
+		"/dbaas_opensearch_metadata":                  "index",
+		"/dbaas_opensearch_metadata/_doc/prefix":      "_doc",
+		"/dbaas_opensearch_metadata/_search":          "_search",
+		"/_cat/indices/prefix*":                       "_cat",
+		"/_plugins/_security/api/internalusers/user":  "_plugins/_security/api/internalusers",
+		"/_plugins/_security/api/internalusers":       "_plugins/_security/api/internalusers",
+		"/_snapshot/snapshots/_verify":                "_snapshot",
+		"/_nodes/reload_secure_settings":              "_nodes",
+		"/_index_template/prefix_template":            "_index_template",
+		"/prefix_orders/_aliases/prefix_orders_alias": "_aliases",
+	}
+	for path, expected := range paths {
+		assert.Equal(t, expected, apiName(path), path)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(Middleware)
+	router.HandleFunc("/databases/{dbName}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/databases/first", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/databases/second", nil))
+
+	counter := httpRequestsTotal.WithLabelValues("/databases/{dbName}", http.MethodGet, "404")
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter))
+}
+
+func TestInstrumentedClient(t *testing.T) {
+	client := NewInstrumentedClient("opensearch-east", common.NewClient())
+	request := httptest.NewRequest(http.MethodGet, "/_plugins/_security/api/internalusers/admin", nil)
+	_, err := client.Perform(request)
+	assert.NoError(t, err)
+	counter := opensearchRequestsTotal.WithLabelValues("opensearch-east", "_plugins/_security/api/internalusers", http.MethodGet)
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter))
+}
+
+func TestSetUsersRecoveryState(t *testing.T) {
+	SetUsersRecoveryState("opensearch-east", "running", "idle", "running", "done")
+	SetUsersRecoveryState("opensearch-west", "running", "idle", "running", "done")
+	SetUsersRecoveryState("opensearch-east", "done", "idle", "running", "done")
+	assert.Equal(t, float64(0), testutil.ToFloat64(usersRecoveryState.WithLabelValues("opensearch-east", "running")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(usersRecoveryState.WithLabelValues("opensearch-east", "done")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(usersRecoveryState.WithLabelValues("opensearch-west", "running")))
+}
+
+func TestObserveRoleDrift(t *testing.T) {
+	ObserveRoleDrift("opensearch-east", "role", "dbaas_admin_role", true)
+	ObserveRoleDrift("opensearch-east", "role", "dbaas_admin_role", false)
+	ObserveRoleDrift("opensearch-west", "role", "dbaas_admin_role", true)
+	assert.Equal(t, float64(0), testutil.ToFloat64(roleDrift.WithLabelValues("opensearch-east", "role", "dbaas_admin_role")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(roleDriftsTotal.WithLabelValues("opensearch-east", "role", "dbaas_admin_role")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(roleDrift.WithLabelValues("opensearch-west", "role", "dbaas_admin_role")))
+}
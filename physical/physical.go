@@ -28,6 +28,7 @@ import (
 	"github.com/Netcracker/dbaas-opensearch-adapter/basic"
 	cl "github.com/Netcracker/dbaas-opensearch-adapter/client"
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/Netcracker/dbaas-opensearch-adapter/metrics"
 	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -47,6 +48,7 @@ type ApiVersionInfo struct {
 
 type RegistrationProvider struct {
 	ApiVersion             string
+	Labels                 map[string]string
 	dbaasAdapter           *common.Component
 	dbaasAggregator        *common.Component
 	physicalDatabaseId     string
@@ -60,7 +62,12 @@ type RegistrationProvider struct {
 
 	// mutex is used to synchronize concurrent registrations.
 	mutex    sync.Mutex
-	executor common.BackgroundExecutor
+	executor *common.BackgroundExecutor
+
+	// stop is closed to finish periodic registration, stopped is closed when periodic registration is finished
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
 
 	// baseProvider is used for migration on multi-user approach
 	baseProvider *basic.BaseProvider
@@ -71,7 +78,7 @@ func NewRegistrationProvider(aggregatorAddress string, aggregatorCredentials dao
 	registrationRetryTime int, registrationRetryDelay int, physicalDatabaseId string,
 	adapterAddress string, adapterCredentials dao.BasicAuth, baseProvider *basic.BaseProvider) *RegistrationProvider {
 	if client == nil {
-		client = cl.ConfigureClient()
+		client = cl.ConfigureClient(context.Background())
 	}
 	apiVersion := getApiVersion(aggregatorAddress, client)
 	baseProvider.ApiVersion = apiVersion
@@ -94,7 +101,8 @@ func NewRegistrationProvider(aggregatorAddress string, aggregatorCredentials dao
 		registrationRetryDelay: registrationRetryDelay,
 		client:                 client,
 		Health:                 common.ComponentHealth{Status: "UNKNOWN"},
-		executor:               common.BackgroundExecutor{},
+		executor:               common.NewBackgroundExecutor(),
+		stop:                   make(chan struct{}),
 		status:                 dao.StatusRunning,
 		baseProvider:           baseProvider,
 	}
@@ -134,16 +142,42 @@ func getApiVersion(aggregatorAddress string, client *http.Client) string {
 }
 
 func (rs *RegistrationProvider) StartRegistration() {
+	rs.stopped = make(chan struct{})
 	go rs.registerPeriodically()
 }
 
 func (rs *RegistrationProvider) registerPeriodically() {
+	defer close(rs.stopped)
 	for {
 		rs.register()
-		time.Sleep(time.Duration(rs.registrationFixedDelay) * time.Millisecond)
+		select {
+		case <-rs.stop:
+			return
+		case <-time.After(time.Duration(rs.registrationFixedDelay) * time.Millisecond):
+		}
 	}
 }
 
+// Stop finishes periodic registration, shuts down the executor of force registrations and waits
+// until registrations in progress are finished or the context is done.
+func (rs *RegistrationProvider) Stop(ctx context.Context) error {
+	rs.stopOnce.Do(func() {
+		close(rs.stop)
+		rs.executor.Shutdown()
+	})
+	if rs.stopped != nil {
+		select {
+		case <-rs.stopped:
+		case <-ctx.Done():
+			return fmt.Errorf("periodic registration is not finished: %w", ctx.Err())
+		}
+	}
+	if err := rs.executor.AwaitTermination(ctx); err != nil {
+		return fmt.Errorf("force registration is not finished: %w", err)
+	}
+	return nil
+}
+
 // register performs one physical database registration attempt and sets the corresponding health status
 // depending on the result.
 func (rs *RegistrationProvider) register() {
@@ -208,9 +242,11 @@ func (rs *RegistrationProvider) doRegistrationRequest() {
 			//}
 			logger.InfoContext(ctx, fmt.Sprintf("Recovered from physical database registration panic, set health PROBLEM: %s", message))
 			rs.Health = common.ComponentHealth{Status: "PROBLEM"}
+			metrics.ObserveRegistration(rs.physicalDatabaseId, metrics.FailureOutcome)
 		} else {
 			logger.InfoContext(ctx, "Successfully registered physical database, set health OK")
 			rs.Health = common.ComponentHealth{Status: "OK"}
+			metrics.ObserveRegistration(rs.physicalDatabaseId, metrics.SuccessOutcome)
 		}
 	}()
 	method, url, body := rs.prepareRequestParameters(ctx)
@@ -394,19 +430,34 @@ func (rs *RegistrationProvider) doMigrationRequest(instructionID string, request
 	return response, nil
 }
 
+// ReadLabelsFile returns labels from the labels file, Labels of the provider are added to them and override them.
 func (rs *RegistrationProvider) ReadLabelsFile(ctx context.Context) map[string]string {
 	var labels map[string]string
 	file, err := os.ReadFile(rs.labelsFileLocation)
 	if err != nil {
 		logger.WarnContext(ctx, fmt.Sprintf("Skipping labels file, cannot read it: %s", rs.labelsFileLocation))
-		return labels
+		return rs.mergeLabels(labels)
 	}
 	err = json.Unmarshal(file, &labels)
 	if err != nil {
 		logger.WarnContext(ctx, fmt.Sprintf("Failed to parse labels file %s", rs.labelsFileLocation), slog.Any("error", err))
 	}
 	logger.DebugContext(ctx, fmt.Sprintf("Read labels: %v", labels))
-	return labels
+	return rs.mergeLabels(labels)
+}
+
+func (rs *RegistrationProvider) mergeLabels(labels map[string]string) map[string]string {
+	if len(rs.Labels) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(labels)+len(rs.Labels))
+	for key, value := range labels {
+		merged[key] = value
+	}
+	for key, value := range rs.Labels {
+		merged[key] = value
+	}
+	return merged
 }
 
 func (rs *RegistrationProvider) ForceRegistrationHandler() func(w http.ResponseWriter, r *http.Request) {
@@ -456,8 +507,7 @@ func (rs *RegistrationProvider) GetPhysicalDatabaseHandler() func(w http.Respons
 		responseBody, err := json.Marshal(physicalDatabase)
 		if err != nil {
 			logger.ErrorContext(ctx, "Failed to marshal physical database response to json", slog.Any("error", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			_, _ = w.Write([]byte(err.Error()))
+			common.WriteError(w, err, ctx)
 			return
 		}
 		_, _ = w.Write(responseBody)
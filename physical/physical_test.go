@@ -15,9 +15,14 @@
 package physical
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Netcracker/dbaas-opensearch-adapter/basic"
 	cl "github.com/Netcracker/dbaas-opensearch-adapter/client"
@@ -94,6 +99,51 @@ func TestFailedRegistration(t *testing.T) {
 	assert.Equal(t, registrationService.Health, common.ComponentHealth{Status: "PROBLEM"})
 }
 
+func TestStopRegistration(t *testing.T) {
+	var registrations atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut {
+			registrations.Add(1)
+		}
+		res.WriteHeader(200)
+	}))
+	defer func() { testServer.Close() }()
+
+	registrationService := NewRegistrationProvider(
+		testServer.URL,
+		dao.BasicAuth{
+			Username: "cluster-dba",
+			Password: "test",
+		},
+		"",
+		nil,
+		150000,
+		60000,
+		5000,
+		"tmp-test",
+		"http://dbaas-opensearch-adapter.elasticsearch-cluster:8080",
+		dao.BasicAuth{
+			Username: "dbaas-aggregator",
+			Password: "dbaas-aggregator",
+		},
+		basic.NewBaseProvider(nil),
+	)
+	registrationService.ApiVersion = common.ApiV1
+	registrationService.StartRegistration()
+	registrationService.executor.Submit(registrationService.RegisterWithRetry)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := registrationService.Stop(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), registrations.Load())
+
+	// registrations submitted after stop are ignored
+	registrationService.executor.Submit(registrationService.RegisterWithRetry)
+	assert.NoError(t, registrationService.Stop(ctx))
+	assert.Equal(t, int32(2), registrations.Load())
+}
+
 func TestApiVersion(t *testing.T) {
 
 	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
@@ -104,7 +154,7 @@ func TestApiVersion(t *testing.T) {
 	}))
 	defer func() { testServer.Close() }()
 
-	apiVersion := getApiVersion(testServer.URL, cl.ConfigureClient())
+	apiVersion := getApiVersion(testServer.URL, cl.ConfigureClient(context.Background()))
 	assert.Equal(t, apiVersion, common.ApiV2)
 }
 
@@ -118,7 +168,7 @@ func TestApiVersionWhenV3NotSupported(t *testing.T) {
 	}))
 	defer func() { testServer.Close() }()
 
-	apiVersion := getApiVersion(testServer.URL, cl.ConfigureClient())
+	apiVersion := getApiVersion(testServer.URL, cl.ConfigureClient(context.Background()))
 	assert.Equal(t, apiVersion, common.ApiV1)
 }
 
@@ -132,7 +182,7 @@ func TestApiVersionWithIncorrectData(t *testing.T) {
 	}))
 	defer func() { testServer.Close() }()
 
-	apiVersion := getApiVersion(testServer.URL, cl.ConfigureClient())
+	apiVersion := getApiVersion(testServer.URL, cl.ConfigureClient(context.Background()))
 	assert.Equal(t, apiVersion, common.ApiV2)
 }
 
@@ -146,6 +196,18 @@ func TestApiVersionWhenApiVersionNotFound(t *testing.T) {
 	}))
 	defer func() { testServer.Close() }()
 
-	apiVersion := getApiVersion(testServer.URL, cl.ConfigureClient())
+	apiVersion := getApiVersion(testServer.URL, cl.ConfigureClient(context.Background()))
 	assert.Equal(t, apiVersion, common.ApiV1)
 }
+
+func TestReadLabelsWithProviderLabels(t *testing.T) {
+	labelsFile := filepath.Join(t.TempDir(), "labels.json")
+	err := os.WriteFile(labelsFile, []byte(`{"region":"east","tier":"standard"}`), 0600)
+	assert.NoError(t, err)
+	registrationService := &RegistrationProvider{
+		labelsFileLocation: labelsFile,
+		Labels:             map[string]string{"tier": "premium", "cluster": "opensearch-1"},
+	}
+	labels := registrationService.ReadLabelsFile(context.Background())
+	assert.Equal(t, map[string]string{"region": "east", "tier": "premium", "cluster": "opensearch-1"}, labels)
+}
@@ -0,0 +1,139 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/cluster"
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+)
+
+// clusterIdPattern restricts physical database identifiers, because they are used in paths of the API
+var clusterIdPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ClustersConfig is the content of the clusters configuration file which lists OpenSearch clusters served by one adapter.
+type ClustersConfig struct {
+	Clusters []ClusterConfig `json:"clusters"`
+}
+
+// ClusterConfig describes one OpenSearch cluster, it is registered in DBaaS aggregator as physical database with Id identifier.
+type ClusterConfig struct {
+	Id             string            `json:"id"`
+	Host           string            `json:"host"`
+	Port           int               `json:"port,omitempty"`
+	Protocol       string            `json:"protocol,omitempty"`
+	Nodes          []string          `json:"nodes,omitempty"`
+	Username       string            `json:"username,omitempty"`
+	Password       string            `json:"password,omitempty"`
+	ClientCertFile string            `json:"clientCertFile,omitempty"`
+	ClientKeyFile  string            `json:"clientKeyFile,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Backup         BackupConfig      `json:"backup"`
+}
+
+// BackupConfig describes curator which performs backups of the cluster and the snapshot repository of the cluster.
+type BackupConfig struct {
+	CuratorAddress    string `json:"curatorAddress,omitempty"`
+	CuratorUsername   string `json:"curatorUsername,omitempty"`
+	CuratorPassword   string `json:"curatorPassword,omitempty"`
+	CuratorCACertFile string `json:"curatorCaCertFile,omitempty"`
+	Repository        string `json:"repository,omitempty"`
+	RepositoryRoot    string `json:"repositoryRoot,omitempty"`
+}
+
+// loadClusters reads the clusters configuration file, no clusters are returned if the path is empty.
+func loadClusters(path string) ([]ClusterConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clusters configuration file: %w", err)
+	}
+	var config ClustersConfig
+	if err = json.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse clusters configuration file '%s': %w", path, err)
+	}
+	if len(config.Clusters) == 0 {
+		return nil, fmt.Errorf("clusters configuration file '%s' does not contain clusters", path)
+	}
+	ids := make(map[string]bool, len(config.Clusters))
+	curators := make(map[string]string, len(config.Clusters))
+	for i := range config.Clusters {
+		clusterConfig := &config.Clusters[i]
+		if !clusterIdPattern.MatchString(clusterConfig.Id) {
+			return nil, fmt.Errorf("cluster id '%s' must match '%s' pattern", clusterConfig.Id, clusterIdPattern)
+		}
+		if ids[clusterConfig.Id] {
+			return nil, fmt.Errorf("cluster id '%s' is not unique", clusterConfig.Id)
+		}
+		ids[clusterConfig.Id] = true
+		if clusterConfig.Host == "" && len(clusterConfig.Nodes) == 0 {
+			return nil, fmt.Errorf("host or nodes must be specified for '%s' cluster", clusterConfig.Id)
+		}
+		if clusterConfig.Port == 0 {
+			clusterConfig.Port = 9200
+		}
+		if clusterConfig.Protocol == "" {
+			clusterConfig.Protocol = common.Http
+		}
+		// curator restores snapshots into its own cluster only, so sharing it would restore backups into a wrong cluster
+		if address := clusterConfig.backupConfig().CuratorAddress; address != "" {
+			if id, ok := curators[address]; ok {
+				return nil, fmt.Errorf("curator '%s' is used by both '%s' and '%s' clusters", address, id, clusterConfig.Id)
+			}
+			curators[address] = clusterConfig.Id
+		}
+	}
+	return config.Clusters, nil
+}
+
+// opensearchConfig returns connection configuration of the cluster, settings which are not specified in the clusters
+// configuration file are taken from environment variables.
+func (c ClusterConfig) opensearchConfig() cluster.Config {
+	config := defaultOpensearchConfig()
+	config.Host = c.Host
+	config.Port = c.Port
+	config.Protocol = c.Protocol
+	config.Nodes = c.Nodes
+	config.Username = c.Username
+	config.Password = c.Password
+	config.ClientCertFile = c.ClientCertFile
+	config.ClientKeyFile = c.ClientKeyFile
+	return config
+}
+
+// backupConfig returns backup configuration of the cluster. Curator settings are taken from environment variables
+// if curator address is not specified, repository settings which are not specified are taken from environment variables.
+func (c ClusterConfig) backupConfig() BackupConfig {
+	config := defaultBackupConfig()
+	if c.Backup.CuratorAddress != "" {
+		config.CuratorAddress = c.Backup.CuratorAddress
+		config.CuratorUsername = c.Backup.CuratorUsername
+		config.CuratorPassword = c.Backup.CuratorPassword
+		config.CuratorCACertFile = c.Backup.CuratorCACertFile
+	}
+	if c.Backup.Repository != "" {
+		config.Repository = c.Backup.Repository
+	}
+	if c.Backup.RepositoryRoot != "" {
+		config.RepositoryRoot = c.Backup.RepositoryRoot
+	}
+	return config
+}
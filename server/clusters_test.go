@@ -0,0 +1,104 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeClustersConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "clusters.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadClusters(t *testing.T) {
+	path := writeClustersConfig(t, `{"clusters":[
+		{"id":"opensearch-1","host":"opensearch.opensearch-1","username":"admin","password":"admin","labels":{"region":"east"}},
+		{"id":"opensearch-2","nodes":["https://opensearch-0.opensearch-2:9200"],"protocol":"https","clientCertFile":"/certs/tls.crt","clientKeyFile":"/certs/tls.key"}
+	]}`)
+	clusters, err := loadClusters(path)
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+
+	assert.Equal(t, "opensearch-1", clusters[0].Id)
+	assert.Equal(t, map[string]string{"region": "east"}, clusters[0].Labels)
+	config := clusters[0].opensearchConfig()
+	assert.Equal(t, "opensearch.opensearch-1", config.Host)
+	assert.Equal(t, 9200, config.Port)
+	assert.Equal(t, common.Http, config.Protocol)
+	assert.Equal(t, "admin", config.Username)
+	assert.Equal(t, opensearchMaxRetries, config.MaxRetries)
+
+	config = clusters[1].opensearchConfig()
+	assert.Equal(t, []string{"https://opensearch-0.opensearch-2:9200"}, config.Nodes)
+	assert.Equal(t, common.Https, config.Protocol)
+	assert.Equal(t, "/certs/tls.crt", config.ClientCertFile)
+}
+
+func TestClusterBackupConfig(t *testing.T) {
+	path := writeClustersConfig(t, `{"clusters":[
+		{"id":"opensearch-1","host":"opensearch.opensearch-1","backup":{"curatorAddress":"http://curator.opensearch-1:8080",
+			"curatorUsername":"backup","curatorPassword":"secret","curatorCaCertFile":"/tls/opensearch-1/ca.crt","repositoryRoot":"/snapshots"}},
+		{"id":"opensearch-2","host":"opensearch.opensearch-2","backup":{"repository":"opensearch-2-backups"}}
+	]}`)
+	clusters, err := loadClusters(path)
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+
+	assert.Equal(t, BackupConfig{
+		CuratorAddress:    "http://curator.opensearch-1:8080",
+		CuratorUsername:   "backup",
+		CuratorPassword:   "secret",
+		CuratorCACertFile: "/tls/opensearch-1/ca.crt",
+		Repository:        opensearchRepo,
+		RepositoryRoot:    "/snapshots",
+	}, clusters[0].backupConfig())
+
+	config := clusters[1].backupConfig()
+	assert.Equal(t, curatorAddress, config.CuratorAddress)
+	assert.Equal(t, "opensearch-2-backups", config.Repository)
+	assert.Equal(t, opensearchRepoRoot, config.RepositoryRoot)
+}
+
+func TestLoadClustersWithoutFile(t *testing.T) {
+	clusters, err := loadClusters("")
+	assert.NoError(t, err)
+	assert.Empty(t, clusters)
+
+	_, err = loadClusters(filepath.Join(t.TempDir(), "absent.json"))
+	assert.ErrorContains(t, err, "failed to read clusters configuration file")
+}
+
+func TestLoadInvalidClusters(t *testing.T) {
+	configs := map[string]string{
+		`{"clusters":[]}`: "does not contain clusters",
+		`{"clusters":[{"id":"opensearch/1","host":"opensearch"}]}`:                              "cluster id 'opensearch/1' must match",
+		`{"clusters":[{"id":"opensearch","host":"first"},{"id":"opensearch","host":"second"}]}`: "cluster id 'opensearch' is not unique",
+		`{"clusters":[{"id":"opensearch"}]}`:                                                    "host or nodes must be specified for 'opensearch' cluster",
+		`{"clusters":{}}`:                                                                       "failed to parse clusters configuration file",
+		`{"clusters":[{"id":"first","host":"first","backup":{"curatorAddress":"http://curator:8080"}},{"id":"second","host":"second","backup":{"curatorAddress":"http://curator:8080"}}]}`: "curator 'http://curator:8080' is used by both 'first' and 'second' clusters",
+	}
+	for content, expectedError := range configs {
+		_, err := loadClusters(writeClustersConfig(t, content))
+		assert.ErrorContains(t, err, expectedError, content)
+	}
+}
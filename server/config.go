@@ -0,0 +1,163 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Config describes on which addresses and with which TLS settings the adapter serves its REST API.
+type Config struct {
+	Host         string
+	HttpEnabled  bool
+	HttpPort     int
+	HttpsEnabled bool
+	HttpsPort    int
+	CertFile     string
+	KeyFile      string
+	// CAFile is used to verify client certificates if clients present them
+	CAFile        string
+	MinTLSVersion string
+	// CipherSuites is a comma-separated list of cipher suite names, default Go suites are used if it is empty
+	CipherSuites string
+}
+
+// NewConfig returns the configuration defined by environment variables.
+// HTTPS is enabled by TLS_ENABLED, HTTP is enabled by default only when HTTPS is disabled.
+func NewConfig() *Config {
+	httpsEnabled, _ := strconv.ParseBool(common.GetEnv("TLS_ENABLED", "false"))
+	httpEnabled, err := strconv.ParseBool(common.GetEnv("HTTP_ENABLED", ""))
+	if err != nil {
+		httpEnabled = !httpsEnabled
+	}
+	return &Config{
+		Host:          common.GetEnv("LISTEN_HOST", ""),
+		HttpEnabled:   httpEnabled,
+		HttpPort:      common.GetIntEnv("HTTP_PORT", 8080),
+		HttpsEnabled:  httpsEnabled,
+		HttpsPort:     common.GetIntEnv("HTTPS_PORT", 8443),
+		CertFile:      common.GetEnv("TLS_CERT_FILE", fmt.Sprintf("%s/tls.crt", certificatesFolder)),
+		KeyFile:       common.GetEnv("TLS_KEY_FILE", fmt.Sprintf("%s/tls.key", certificatesFolder)),
+		CAFile:        common.GetEnv("TLS_CA_FILE", ""),
+		MinTLSVersion: common.GetEnv("TLS_MIN_VERSION", "1.2"),
+		CipherSuites:  common.GetEnv("TLS_CIPHER_SUITES", ""),
+	}
+}
+
+// RegisterFlags defines command line flags which override values received from environment variables.
+func (c *Config) RegisterFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&c.Host, "listen-host", c.Host, "Host to listen on, all interfaces are used if it is empty")
+	flagSet.BoolVar(&c.HttpEnabled, "http-enabled", c.HttpEnabled, "Enables HTTP listener")
+	flagSet.IntVar(&c.HttpPort, "http-port", c.HttpPort, "Port of HTTP listener")
+	flagSet.BoolVar(&c.HttpsEnabled, "https-enabled", c.HttpsEnabled, "Enables HTTPS listener")
+	flagSet.IntVar(&c.HttpsPort, "https-port", c.HttpsPort, "Port of HTTPS listener")
+	flagSet.StringVar(&c.CertFile, "tls-cert-file", c.CertFile, "Path to TLS certificate of HTTPS listener")
+	flagSet.StringVar(&c.KeyFile, "tls-key-file", c.KeyFile, "Path to TLS private key of HTTPS listener")
+	flagSet.StringVar(&c.CAFile, "tls-ca-file", c.CAFile, "Path to CA certificate to verify client certificates of HTTPS listener")
+	flagSet.StringVar(&c.MinTLSVersion, "tls-min-version", c.MinTLSVersion, "Minimum TLS version of HTTPS listener: 1.0, 1.1, 1.2 or 1.3")
+	flagSet.StringVar(&c.CipherSuites, "tls-cipher-suites", c.CipherSuites, "Comma-separated list of TLS cipher suites of HTTPS listener")
+}
+
+func (c *Config) Validate() error {
+	if !c.HttpEnabled && !c.HttpsEnabled {
+		return errors.New("at least one of HTTP and HTTPS listeners must be enabled")
+	}
+	if c.HttpEnabled && c.HttpsEnabled && c.HttpPort == c.HttpsPort {
+		return fmt.Errorf("HTTP and HTTPS listeners cannot use the same port %d", c.HttpPort)
+	}
+	if c.HttpsEnabled {
+		if _, err := c.TLSConfig(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) HttpAddress() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.HttpPort))
+}
+
+func (c *Config) HttpsAddress() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.HttpsPort))
+}
+
+// TLSConfig builds TLS configuration of HTTPS listener, certificate and key are loaded by the listener itself.
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	minVersion, ok := tlsVersions[c.MinTLSVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported minimum TLS version '%s', allowed values are 1.0, 1.1, 1.2 and 1.3", c.MinTLSVersion)
+	}
+	tlsConfig := &tls.Config{MinVersion: minVersion}
+	if c.CipherSuites != "" {
+		cipherSuites, err := parseCipherSuites(c.CipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.CipherSuites = cipherSuites
+	}
+	if c.CAFile != "" {
+		caCert, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificates found in '%s'", c.CAFile)
+		}
+		tlsConfig.ClientCAs = caCertPool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+func parseCipherSuites(names string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	var cipherSuites []uint16
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS cipher suite '%s'", name)
+		}
+		cipherSuites = append(cipherSuites, id)
+	}
+	return cipherSuites, nil
+}
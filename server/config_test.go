@@ -0,0 +1,80 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	t.Setenv("TLS_ENABLED", "true")
+	t.Setenv("HTTPS_PORT", "9443")
+	config := NewConfig()
+	assert.False(t, config.HttpEnabled)
+	assert.True(t, config.HttpsEnabled)
+	assert.Equal(t, ":9443", config.HttpsAddress())
+	assert.Equal(t, "/tls/tls.crt", config.CertFile)
+
+	t.Setenv("HTTP_ENABLED", "true")
+	t.Setenv("LISTEN_HOST", "127.0.0.1")
+	config = NewConfig()
+	assert.True(t, config.HttpEnabled)
+	assert.Equal(t, "127.0.0.1:8080", config.HttpAddress())
+}
+
+func TestConfigFlags(t *testing.T) {
+	config := &Config{HttpEnabled: true, HttpPort: 8080, MinTLSVersion: "1.2"}
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.RegisterFlags(flagSet)
+	err := flagSet.Parse([]string{"-https-enabled", "-https-port", "8443", "-tls-min-version", "1.3"})
+	assert.NoError(t, err)
+	assert.True(t, config.HttpEnabled)
+	assert.True(t, config.HttpsEnabled)
+	assert.Equal(t, 8443, config.HttpsPort)
+	assert.NoError(t, config.Validate())
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := &Config{}
+	assert.EqualError(t, config.Validate(), "at least one of HTTP and HTTPS listeners must be enabled")
+
+	config = &Config{HttpEnabled: true, HttpPort: 8080, HttpsEnabled: true, HttpsPort: 8080, MinTLSVersion: "1.2"}
+	assert.EqualError(t, config.Validate(), "HTTP and HTTPS listeners cannot use the same port 8080")
+
+	config = &Config{HttpsEnabled: true, MinTLSVersion: "1.4"}
+	assert.EqualError(t, config.Validate(), "unsupported minimum TLS version '1.4', allowed values are 1.0, 1.1, 1.2 and 1.3")
+
+	config = &Config{HttpsEnabled: true, MinTLSVersion: "1.2", CipherSuites: "TLS_UNKNOWN"}
+	assert.EqualError(t, config.Validate(), "unsupported TLS cipher suite 'TLS_UNKNOWN'")
+
+	config = &Config{HttpsEnabled: true, MinTLSVersion: "1.2", CAFile: "/nonexistent/ca.crt"}
+	assert.ErrorContains(t, config.Validate(), "failed to read CA certificate")
+}
+
+func TestTLSConfig(t *testing.T) {
+	config := &Config{
+		MinTLSVersion: "1.2",
+		CipherSuites:  "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	}
+	tlsConfig, err := config.TLSConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, tlsConfig.CipherSuites)
+	assert.Nil(t, tlsConfig.ClientCAs)
+}
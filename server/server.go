@@ -17,21 +17,28 @@ package server
 import (
 	"context"
 	"crypto/subtle"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/Netcracker/dbaas-opensearch-adapter/backup"
 	"github.com/Netcracker/dbaas-opensearch-adapter/basic"
+	"github.com/Netcracker/dbaas-opensearch-adapter/certificates"
 	cl "github.com/Netcracker/dbaas-opensearch-adapter/client"
 	"github.com/Netcracker/dbaas-opensearch-adapter/cluster"
 	"github.com/Netcracker/dbaas-opensearch-adapter/common"
 	"github.com/Netcracker/dbaas-opensearch-adapter/health"
+	"github.com/Netcracker/dbaas-opensearch-adapter/metrics"
 	"github.com/Netcracker/dbaas-opensearch-adapter/physical"
 	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
 var (
@@ -48,19 +55,71 @@ var (
 	opensearchProtocol               = common.GetEnv("OPENSEARCH_PROTOCOL", common.Http)
 	opensearchUsername               = common.GetEnv("OPENSEARCH_USERNAME", "opensearch")
 	opensearchPassword               = common.GetEnv("OPENSEARCH_PASSWORD", "change")
+	opensearchClientCertFile         = common.GetEnv("OPENSEARCH_CLIENT_CERT_FILE", "")
+	opensearchClientKeyFile          = common.GetEnv("OPENSEARCH_CLIENT_KEY_FILE", "")
 	opensearchRepo                   = common.GetEnv("OPENSEARCH_REPO", "dbaas-backups-repository")
 	opensearchRepoRoot               = common.GetEnv("OPENSEARCH_REPO_ROOT", "/usr/share/opensearch/")
+	curatorAddress                   = common.GetEnv("CURATOR_ADDRESS", "")
+	curatorUsername                  = common.GetEnv("CURATOR_USERNAME", "")
+	curatorPassword                  = common.GetEnv("CURATOR_PASSWORD", "")
 	enhancedSecurityPluginEnabled, _ = strconv.ParseBool(common.GetEnv("ENHANCED_SECURITY_PLUGIN_ENABLED", "false"))
 
+	opensearchNodes                   = cluster.ParseNodes(common.GetEnv("OPENSEARCH_NODES", ""))
+	opensearchAdvertiseNodes, _       = strconv.ParseBool(common.GetEnv("OPENSEARCH_ADVERTISE_NODES", "false"))
+	opensearchDiscoverNodesOnStart, _ = strconv.ParseBool(common.GetEnv("OPENSEARCH_DISCOVER_NODES_ON_START", "false"))
+	opensearchDiscoverNodesInterval   = common.GetIntEnv("OPENSEARCH_DISCOVER_NODES_INTERVAL_MS", 0)
+	opensearchMaxRetries              = common.GetIntEnv("OPENSEARCH_MAX_RETRIES", 3)
+	opensearchRetryBackoff            = common.GetIntEnv("OPENSEARCH_RETRY_BACKOFF_MS", 100)
+
 	labelsFilename    = common.GetEnv("LABELS_FILE_LOCATION_NAME", "dbaas.physical_databases.registration.labels.json")
 	labelsLocationDir = common.GetEnv("LABELS_FILE_LOCATION_DIR", "/app/config/")
 
 	registrationEnabled, _ = strconv.ParseBool(common.GetEnv("REGISTRATION_ENABLED", "false"))
+
+	clustersConfigFile = common.GetEnv("CLUSTERS_CONFIG_FILE", "")
+
+	roleTypesConfigFile = common.GetEnv("ROLE_TYPES_CONFIG_FILE", "")
+
+	roleReconcileInterval      = common.GetIntEnv("ROLE_RECONCILE_INTERVAL_MS", 300000)
+	roleReconcileReportOnly, _ = strconv.ParseBool(common.GetEnv("ROLE_RECONCILE_REPORT_ONLY", "false"))
+
+	shutdownTimeout = common.GetIntEnv("SHUTDOWN_TIMEOUT_MS", 30000)
+
+	softDeleteRetentionHours = common.GetIntEnv("SOFT_DELETE_RETENTION_HOURS", 0)
+	softDeleteReaperInterval = common.GetIntEnv("SOFT_DELETE_REAPER_INTERVAL_MS", 600000)
+
+	quotaEnforcementInterval = common.GetIntEnv("QUOTA_ENFORCEMENT_INTERVAL_MS", 60000)
+
+	expiredUsersSweepInterval = common.GetIntEnv("EXPIRED_USERS_SWEEP_INTERVAL_MS", 60000)
+
+	passwordLength               = common.GetIntEnv("PASSWORD_LENGTH", 10)
+	passwordDigits               = common.GetIntEnv("PASSWORD_DIGITS", 1)
+	passwordSymbols              = common.GetIntEnv("PASSWORD_SYMBOLS", 1)
+	passwordSymbolSet            = common.GetEnv("PASSWORD_SYMBOL_SET", "_#$@")
+	passwordValidateByCluster, _ = strconv.ParseBool(common.GetEnv("PASSWORD_VALIDATE_CLUSTER_REGEX", "false"))
+
+	logger = common.GetLogger()
 )
 
 const certificatesFolder = "/tls"
 
-func Server(adapterAddress string, adapterUsername string, adapterPassword string) error {
+func Server(config *Config, adapterAddress string, adapterUsername string, adapterPassword string) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	// TLS configuration is loaded before background processes are started, so its errors do not leave them running
+	var tlsConfig *tls.Config
+	var keyPair *certificates.KeyPair
+	if config.HttpsEnabled {
+		var err error
+		if tlsConfig, err = config.TLSConfig(); err != nil {
+			return err
+		}
+		if keyPair, err = certificates.NewKeyPair("", "server", config.CertFile, config.KeyFile); err != nil {
+			return fmt.Errorf("failed to load server certificate: %w", err)
+		}
+		tlsConfig.GetCertificate = keyPair.GetCertificate
+	}
 	adapter := common.Component{
 		Address: adapterAddress,
 		Credentials: dao.BasicAuth{
@@ -68,42 +127,201 @@ func Server(adapterAddress string, adapterUsername string, adapterPassword strin
 			Password: adapterPassword,
 		},
 	}
-	server := &http.Server{
-		Addr:    ":8080",
-		Handler: Handlers(adapter),
+	handler, shutdown := Handlers(adapter)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	var servers []*http.Server
+	serverErrors := make(chan error, 2)
+	if config.HttpEnabled {
+		server := &http.Server{
+			Addr:    config.HttpAddress(),
+			Handler: handler,
+		}
+		servers = append(servers, server)
+		logger.Info(fmt.Sprintf("Serving HTTP on %s", server.Addr))
+		go func() {
+			serverErrors <- server.ListenAndServe()
+		}()
 	}
-	if strings.Contains(adapterAddress, common.Https) {
-		server.Addr = ":8443"
-		return server.ListenAndServeTLS(fmt.Sprintf("%s/tls.crt", certificatesFolder), fmt.Sprintf("%s/tls.key", certificatesFolder))
+	if config.HttpsEnabled {
+		go keyPair.Watch(ctx)
+		server := &http.Server{
+			Addr:      config.HttpsAddress(),
+			Handler:   handler,
+			TLSConfig: tlsConfig,
+		}
+		servers = append(servers, server)
+		logger.Info(fmt.Sprintf("Serving HTTPS on %s", server.Addr))
+		go func() {
+			// certificate and key are provided by GetCertificate
+			serverErrors <- server.ListenAndServeTLS("", "")
+		}()
+	}
+	var serverErr error
+	select {
+	case serverErr = <-serverErrors:
+		logger.Error(fmt.Sprintf("Listener has failed, shutting down: %v", serverErr))
+	case <-ctx.Done():
+		stop()
+		logger.Info("Termination signal is received, shutting down")
 	}
-	return server.ListenAndServe()
+
+	logger.Info(fmt.Sprintf("Shutting down with %d ms timeout", shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(shutdownTimeout)*time.Millisecond)
+	defer cancel()
+	// Stop accepting new requests and wait for in-flight ones before stopping background processes
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			serverErr = errors.Join(serverErr, fmt.Errorf("in-flight requests on %s are not finished: %w", server.Addr, err))
+		}
+	}
+	return errors.Join(serverErr, shutdown(shutdownCtx))
 }
 
-func Handlers(adapter common.Component) http.Handler {
-	opensearch := cluster.NewOpensearch(opensearchHost, opensearchPort,
-		opensearchProtocol, opensearchUsername, opensearchPassword)
+// Handlers configures REST API of the adapter and starts its background processes.
+// It returns the handler and the function which stops background processes.
+// If clusters configuration file is specified, API of each cluster is served under `/clusters/{id}` path.
+func Handlers(adapter common.Component) (http.Handler, func(ctx context.Context) error) {
+	clusters, err := loadClusters(clustersConfigFile)
+	if err != nil {
+		panic(err)
+	}
+
+	r := mux.NewRouter()
+	r.Use(metrics.Middleware)
+
+	r.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+
+	if len(clusters) == 0 {
+		healthService, shutdown := clusterHandlers(r, adapter, defaultOpensearchConfig(), defaultBackupConfig(),
+			dbaasAggregatorPhysicalDatabaseId, nil)
+		r.HandleFunc("/health", healthService.HealthHandler()).Methods(http.MethodGet)
+		return JsonContentType(handlers.CompressHandler(r)), shutdown
+	}
+
+	clustersHealth := health.ClustersHealth{Clusters: make(map[string]*health.Health, len(clusters))}
+	var shutdowns []func(ctx context.Context) error
+	for _, clusterConfig := range clusters {
+		logger.Info(fmt.Sprintf("Configuring API of '%s' cluster", clusterConfig.Id))
+		pathPrefix := fmt.Sprintf("/clusters/%s", clusterConfig.Id)
+		// DBaaS aggregator appends API paths to the registered address, so it must contain the cluster path
+		clusterAdapter := common.Component{
+			Address:     strings.TrimSuffix(adapter.Address, "/") + pathPrefix,
+			Credentials: adapter.Credentials,
+		}
+		healthService, shutdown := clusterHandlers(r.PathPrefix(pathPrefix).Subrouter(), clusterAdapter,
+			clusterConfig.opensearchConfig(), clusterConfig.backupConfig(), clusterConfig.Id, clusterConfig.Labels)
+		clustersHealth.Clusters[clusterConfig.Id] = healthService
+		shutdowns = append(shutdowns, shutdown)
+	}
+	r.HandleFunc("/health", clustersHealth.HealthHandler()).Methods(http.MethodGet)
+
+	shutdown := func(ctx context.Context) error {
+		var errs []error
+		for _, clusterShutdown := range shutdowns {
+			errs = append(errs, clusterShutdown(ctx))
+		}
+		return errors.Join(errs...)
+	}
+	return JsonContentType(handlers.CompressHandler(r)), shutdown
+}
+
+func defaultOpensearchConfig() cluster.Config {
+	return cluster.Config{
+		Host:                  opensearchHost,
+		Port:                  opensearchPort,
+		Protocol:              opensearchProtocol,
+		Username:              opensearchUsername,
+		Password:              opensearchPassword,
+		ClientCertFile:        opensearchClientCertFile,
+		ClientKeyFile:         opensearchClientKeyFile,
+		Nodes:                 opensearchNodes,
+		AdvertiseNodes:        opensearchAdvertiseNodes,
+		DiscoverNodesOnStart:  opensearchDiscoverNodesOnStart,
+		DiscoverNodesInterval: time.Duration(opensearchDiscoverNodesInterval) * time.Millisecond,
+		MaxRetries:            opensearchMaxRetries,
+		RetryBackoff:          time.Duration(opensearchRetryBackoff) * time.Millisecond,
+	}
+}
+
+func defaultBackupConfig() BackupConfig {
+	return BackupConfig{
+		CuratorAddress:  curatorAddress,
+		CuratorUsername: curatorUsername,
+		CuratorPassword: curatorPassword,
+		Repository:      opensearchRepo,
+		RepositoryRoot:  opensearchRepoRoot,
+	}
+}
+
+func passwordPolicy() basic.PasswordPolicy {
+	return basic.PasswordPolicy{
+		Length:       passwordLength,
+		Digits:       passwordDigits,
+		Symbols:      passwordSymbols,
+		SymbolSet:    passwordSymbolSet,
+		ClusterRegex: passwordValidateByCluster,
+	}
+}
+
+// clusterHandlers registers API of one OpenSearch cluster in the router and starts its registration in DBaaS aggregator
+// as physical database with the given identifier.
+func clusterHandlers(r *mux.Router, adapter common.Component, opensearchConfig cluster.Config, backupConfig BackupConfig,
+	physicalDatabaseId string, labels map[string]string) (*health.Health, func(ctx context.Context) error) {
+	opensearchConfig.Id = physicalDatabaseId
+	// watchers of TLS certificates of the cluster are stopped on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	opensearch := cluster.NewOpensearch(ctx, opensearchConfig)
 	baseProvider := basic.NewBaseProvider(opensearch)
+	if err := baseProvider.ConfigurePasswordPolicy(passwordPolicy()); err != nil {
+		panic(err)
+	}
+	roleTypes, err := basic.LoadRoleTypes(roleTypesConfigFile, enhancedSecurityPluginEnabled)
+	if err != nil {
+		panic(err)
+	}
+	if err = baseProvider.ConfigureRoleTypes(roleTypes); err != nil {
+		panic(err)
+	}
 	baseProvider.EnsureAggregationIndex()
+	baseProvider.EnsureRecoveryJobsIndex()
+	baseProvider.LoadRecoveryJob(context.Background())
+	if expiredUsersSweepInterval > 0 {
+		baseProvider.StartExpiredUsersSweeper(time.Duration(expiredUsersSweepInterval) * time.Millisecond)
+	} else {
+		baseProvider.RemoveExpiredUsers(context.Background())
+	}
+	if softDeleteRetentionHours > 0 {
+		baseProvider.ConfigureSoftDelete(time.Duration(softDeleteRetentionHours) * time.Hour)
+		baseProvider.EnsureTombstonesIndex()
+		baseProvider.StartTombstonesReaper(time.Duration(softDeleteReaperInterval) * time.Millisecond)
+	}
 	registrationProvider := startRegistration(adapter.Address, adapter.Credentials.Username,
-		adapter.Credentials.Password, baseProvider)
+		adapter.Credentials.Password, physicalDatabaseId, labels, baseProvider, cl.ConfigureClient(ctx))
 	createBasicRoles(baseProvider)
-	curatorBaseClient := cl.ConfigureCuratorClient()
-	backupProvider := backup.NewBackupProvider(opensearch.Client, curatorBaseClient, opensearchRepoRoot)
+	if roleReconcileInterval > 0 {
+		baseProvider.StartRoleReconciler(time.Duration(roleReconcileInterval)*time.Millisecond, roleReconcileReportOnly)
+	}
+	if quotaEnforcementInterval > 0 {
+		baseProvider.StartQuotaEnforcer(time.Duration(quotaEnforcementInterval) * time.Millisecond)
+	}
+	curatorClient := cl.ConfigureCuratorClient(ctx, physicalDatabaseId, backupConfig.CuratorCACertFile)
+	curator := backup.NewCurator(physicalDatabaseId, backupConfig.CuratorAddress, backupConfig.CuratorUsername,
+		backupConfig.CuratorPassword, curatorClient)
+	backupProvider := backup.NewBackupProvider(opensearch.Client, curator, backupConfig.RepositoryRoot)
 	basePath := fmt.Sprintf("/api/%s/dbaas/adapter/opensearch", registrationProvider.ApiVersion)
 
-	healthService := health.Health{
+	healthService := &health.Health{
 		Status:                common.Up,
 		OpensearchHealth:      opensearch.Health,
 		DbaasAggregatorHealth: &registrationProvider.Health,
 		Opensearch:            opensearch,
 	}
 
-	r := mux.NewRouter()
 	authorizer := BasicAuthorizer(adapter.Credentials.Username, adapter.Credentials.Password,
 		"This API is for using by DBaaS aggregator only")
 
-	r.HandleFunc("/health", healthService.HealthHandler()).Methods(http.MethodGet)
-
 	r.HandleFunc(fmt.Sprintf("%s/supports", basePath), baseProvider.SupportsHandler()).Methods(http.MethodGet)
 
 	r.Handle(fmt.Sprintf("%s/databases", basePath),
@@ -114,10 +332,34 @@ func Handlers(adapter common.Component) http.Handler {
 		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.ListDatabasesHandler())),
 	).Methods(http.MethodGet)
 
+	r.Handle(fmt.Sprintf("%s/databases/describe", basePath),
+		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.DescribeDatabasesHandler())),
+	).Methods(http.MethodPost)
+
 	r.Handle(fmt.Sprintf("%s/resources/bulk-drop", basePath),
 		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.BulkDropResourceHandler())),
 	).Methods(http.MethodPost)
 
+	r.Handle(fmt.Sprintf("%s/databases/{name}/undelete", basePath),
+		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.UndeleteDatabaseHandler())),
+	).Methods(http.MethodPost)
+
+	r.Handle(fmt.Sprintf("%s/databases/usage", basePath),
+		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.GetDatabasesUsageHandler())),
+	).Methods(http.MethodPost)
+
+	r.Handle(fmt.Sprintf("%s/databases/{prefix}/usage", basePath),
+		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.GetDatabaseUsageHandler())),
+	).Methods(http.MethodGet)
+
+	r.Handle(fmt.Sprintf("%s/databases/{prefix}/quota", basePath),
+		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.SetQuotaHandler())),
+	).Methods(http.MethodPut)
+
+	r.Handle(fmt.Sprintf("%s/databases/{prefix}/rotate-credentials", basePath),
+		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.RotateCredentialsHandler())),
+	).Methods(http.MethodPost)
+
 	r.Handle(fmt.Sprintf("%s/databases/{dbName}/metadata", basePath),
 		handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.UpdateMetadataHandler())),
 	).Methods(http.MethodPut)
@@ -127,11 +369,11 @@ func Handlers(adapter common.Component) http.Handler {
 	).Methods(http.MethodPost)
 
 	r.Handle(fmt.Sprintf("%s/backups/{backupID}/restore", basePath),
-		handlers.LoggingHandler(os.Stdout, authorizer(backupProvider.RestoreBackupHandler(opensearchRepo, basePath))),
+		handlers.LoggingHandler(os.Stdout, authorizer(backupProvider.RestoreBackupHandler(backupConfig.Repository, basePath))),
 	).Methods(http.MethodPost)
 
 	r.Handle(fmt.Sprintf("%s/backups/{backupID}/restoration", basePath),
-		handlers.LoggingHandler(os.Stdout, authorizer(backupProvider.RestorationBackupHandler(opensearchRepo, basePath))),
+		handlers.LoggingHandler(os.Stdout, authorizer(backupProvider.RestorationBackupHandler(backupConfig.Repository, basePath))),
 	).Methods(http.MethodPost)
 
 	r.Handle(fmt.Sprintf("%s/backups/track/backup/{backupID}", basePath),
@@ -139,11 +381,11 @@ func Handlers(adapter common.Component) http.Handler {
 	).Methods(http.MethodGet)
 
 	r.Handle(fmt.Sprintf("%s/backups/track/restore/{backupID}", basePath),
-		handlers.LoggingHandler(os.Stdout, authorizer(backupProvider.TrackRestoreFromTrackIdHandler(opensearchRepo))),
+		handlers.LoggingHandler(os.Stdout, authorizer(backupProvider.TrackRestoreFromTrackIdHandler(backupConfig.Repository))),
 	).Methods(http.MethodGet)
 
 	r.Handle(fmt.Sprintf("%s/backups/track/restoring/backups/{backupID}/indices/{indices}", basePath),
-		handlers.LoggingHandler(os.Stdout, authorizer(backupProvider.TrackRestoreFromIndicesHandler(opensearchRepo))),
+		handlers.LoggingHandler(os.Stdout, authorizer(backupProvider.TrackRestoreFromIndicesHandler(backupConfig.Repository))),
 	).Methods(http.MethodGet)
 
 	r.Handle(fmt.Sprintf("%s/backups/{backupID}", basePath),
@@ -174,16 +416,25 @@ func Handlers(adapter common.Component) http.Handler {
 		r.Handle(fmt.Sprintf("%s/users/restore-password/state", basePath),
 			handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.GetRecoveryStateHandler())),
 		).Methods(http.MethodGet)
+
+		r.Handle(fmt.Sprintf("%s/users/restore-password/job", basePath),
+			handlers.LoggingHandler(os.Stdout, authorizer(baseProvider.GetRecoveryJobHandler())),
+		).Methods(http.MethodGet)
 	}
 
-	return JsonContentType(handlers.CompressHandler(r))
+	shutdown := func(ctx context.Context) error {
+		cancel()
+		return errors.Join(registrationProvider.Stop(ctx), baseProvider.Shutdown(ctx))
+	}
+	return healthService, shutdown
 }
 
 func JsonContentType(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() { // error handler, when error occurred it sends request with http status 400 and body with error message
 			if err := recover(); err != nil {
-				http.Error(w, err.(string), http.StatusBadRequest)
+				common.WriteError(w, common.NewError(http.StatusBadRequest, common.InvalidRequestCode,
+					errors.New(fmt.Sprint(err))), common.PrepareContext(r))
 				return
 			}
 		}()
@@ -193,7 +444,8 @@ func JsonContentType(h http.Handler) http.Handler {
 }
 
 func startRegistration(adapterAddress string, adapterUsername string, adapterPassword string,
-	baseProvider *basic.BaseProvider) *physical.RegistrationProvider {
+	physicalDatabaseId string, labels map[string]string, baseProvider *basic.BaseProvider,
+	aggregatorClient *http.Client) *physical.RegistrationProvider {
 	dbaasAggregatorCredentials := dao.BasicAuth{
 		Username: dbaasAggregatorRegistrationUsername,
 		Password: dbaasAggregatorRegistrationPassword,
@@ -206,15 +458,16 @@ func startRegistration(adapterAddress string, adapterUsername string, adapterPas
 		dbaasAggregatorRegistrationAddress,
 		dbaasAggregatorCredentials,
 		labelsLocationDir+labelsFilename,
-		nil,
+		aggregatorClient,
 		dbaasAggregatorRegistrationFixedDelay,
 		dbaasAggregatorRegistrationRetryTime,
 		dbaasAggregatorRegistrationRetryDelay,
-		dbaasAggregatorPhysicalDatabaseId,
+		physicalDatabaseId,
 		adapterAddress,
 		adapterCredentials,
 		baseProvider,
 	)
+	registrationService.Labels = labels
 	if registrationEnabled {
 		registrationService.StartRegistration()
 	}
@@ -227,16 +480,12 @@ func createBasicRoles(baseProvider *basic.BaseProvider) {
 	if err != nil {
 		panic(err)
 	}
-	if err = baseProvider.CreateRoleWithISMPermissions(enhancedSecurityPluginEnabled); err != nil {
-		panic(err)
-	}
-	if err = baseProvider.CreateRoleWithAdminPermissions(); err != nil {
-		panic(err)
-	}
-	if err = baseProvider.CreateRoleWithDMLPermissions(); err != nil {
-		panic(err)
+	for _, roleType := range baseProvider.GetRoleTypes() {
+		if err = baseProvider.CreateRoleType(roleType); err != nil {
+			panic(err)
+		}
 	}
-	if err = baseProvider.CreateRoleWithReadOnlyPermissions(); err != nil {
+	if err = baseProvider.CreateRoleWithQuotaExceededAdminPermissions(); err != nil {
 		panic(err)
 	}
 	// migration is necessary if specific roles mapping does not exist
@@ -250,6 +499,9 @@ func createBasicRoles(baseProvider *basic.BaseProvider) {
 			panic(err)
 		}
 	}
+	if err = baseProvider.CreateOrUpdateRoleMapping(basic.QuotaExceededAdminRoleType); err != nil {
+		panic(err)
+	}
 }
 
 func performMigration(baseProvider *basic.BaseProvider) error {
@@ -304,8 +556,8 @@ func BasicAuthorizer(username string, password string, realm string) func(func(w
 
 			if !ok || subtle.ConstantTimeCompare([]byte(user), []byte(username)) != 1 || subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
 				w.Header().Set("WWW-Authenticate", `Basic realm="`+realm+`"`)
-				w.WriteHeader(http.StatusUnauthorized)
-				_, _ = w.Write([]byte("Not authorized to use this API, only DBaaS aggregator can use it.\n"))
+				common.WriteError(w, common.NewError(http.StatusUnauthorized, common.UnauthorizedCode,
+					errors.New("not authorized to use this API, only DBaaS aggregator can use it")), common.PrepareContext(r))
 				return
 			}
 			h.ServeHTTP(w, r)
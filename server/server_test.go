@@ -0,0 +1,57 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Netcracker/dbaas-opensearch-adapter/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonContentTypeRecoversPanic(t *testing.T) {
+	for _, value := range []interface{}{"invalid request", errors.New("invalid request")} {
+		handler := JsonContentType(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(value)
+		}))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/databases", nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		var response common.Error
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, common.InvalidRequestCode, response.Code)
+		assert.Equal(t, "invalid request", response.Message)
+	}
+}
+
+func TestServerFailsOnInvalidCertificateBeforeStart(t *testing.T) {
+	config := &Config{
+		HttpsEnabled:  true,
+		HttpsPort:     8443,
+		MinTLSVersion: "1.2",
+		CertFile:      filepath.Join(t.TempDir(), "tls.crt"),
+		KeyFile:       filepath.Join(t.TempDir(), "tls.key"),
+	}
+	// the adapter is not configured in the test, so reaching its handlers panics
+	err := Server(config, "http://localhost:8080", "dbaas-adapter", "dbaas-adapter")
+	assert.ErrorContains(t, err, "failed to load server certificate")
+}